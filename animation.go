@@ -0,0 +1,302 @@
+package main
+
+import (
+	"github.com/gonutz/settlers/game"
+	"math"
+)
+
+// animationKind tells what an animation shows. The UI does not draw the real
+// thing while an animation of its kind plays.
+type animationKind int
+
+const (
+	diceAnimation animationKind = iota
+	resourceAnimation
+	pieceAnimation
+	robberAnimation
+)
+
+// animation draws one frame after the other until it is done. The main loop
+// draws 60 frames per second, all times are given in frames.
+type animation struct {
+	kind animationKind
+	// delay is the number of frames to wait before the animation starts
+	delay  int
+	frame  int
+	frames int
+	// onBoard animations are drawn in the zoomed board view, the others in the
+	// HUD view
+	onBoard bool
+	// draw is called with the progress of the animation, from 0 to 1
+	draw func(g *graphics, t float64)
+}
+
+// animator plays animations. While any of them plays, the UI shows the game as
+// it was before they started and does not accept input for the game, the
+// changes become visible when the last animation is done. The zero value is
+// ready to use.
+type animator struct {
+	running []*animation
+	before  *game.Game
+}
+
+// play starts the animations, before is the game state they start from.
+func (a *animator) play(before *game.Game, anims ...*animation) {
+	if len(anims) == 0 {
+		return
+	}
+	if a.before == nil {
+		a.before = before
+	}
+	a.running = append(a.running, anims...)
+}
+
+// update advances all animations by one frame.
+func (a *animator) update() {
+	n := 0
+	for _, anim := range a.running {
+		if anim.delay > 0 {
+			anim.delay--
+		} else {
+			anim.frame++
+		}
+		if anim.frame < anim.frames {
+			a.running[n] = anim
+			n++
+		}
+	}
+	a.running = a.running[:n]
+	if n == 0 {
+		a.before = nil
+	}
+}
+
+func (a *animator) draw(g *graphics, onBoard bool) {
+	for _, anim := range a.running {
+		if anim.delay == 0 && anim.onBoard == onBoard {
+			anim.draw(g, float64(anim.frame)/float64(anim.frames))
+		}
+	}
+}
+
+// busy returns true while any animation plays, input for the game has to wait
+// until then.
+func (a *animator) busy() bool {
+	return len(a.running) > 0
+}
+
+func (a *animator) playing(kind animationKind) bool {
+	for _, anim := range a.running {
+		if anim.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// shownGame returns the game state to draw, that is the one from before the
+// animations while they play.
+func (a *animator) shownGame(current *game.Game) *game.Game {
+	if a.before != nil {
+		return a.before
+	}
+	return current
+}
+
+// animationTargets tells the animations where things are on the screen. The
+// HUD positions can change with the window size, so they are asked for in
+// every frame.
+type animationTargets interface {
+	// boardToHUD converts board to HUD coordinates.
+	boardToHUD(x, y int) (int, int)
+	// dieCenter returns the center of the first (0) or second (1) die.
+	dieCenter(i int) (x, y int)
+	// resourceSymbolCenter returns the center of the resource's symbol in the
+	// resource bar.
+	resourceSymbolCenter(r game.Resource) (x, y int)
+}
+
+// changeAnimations compares two game states and returns the animations that
+// show what happened in between. Only the resources of the shown player fly to
+// the resource bar.
+func changeAnimations(
+	before, after *game.Game,
+	shown game.Color,
+	targets animationTargets,
+) []*animation {
+	var anims []*animation
+
+	if before.State == game.RollingDice && after.State != game.RollingDice {
+		dice := newDiceAnimation(after.Dice, targets)
+		anims = append(anims, dice)
+		anims = append(anims, resourceAnimations(before, after, shown, targets, dice.frames)...)
+	}
+
+	for i, p := range after.GetPlayers() {
+		old := before.Players[i]
+		for _, r := range p.GetBuiltRoads() {
+			if !old.HasRoadOnEdge(r.Position) {
+				anims = append(anims, newRoadDrop(r.Position, p.Color))
+			}
+		}
+		for _, s := range p.GetBuiltSettlements() {
+			if !old.HasBuildingOnCorner(s.Position) {
+				anims = append(anims, newSettlementDrop(s.Position, p.Color))
+			}
+		}
+		for _, c := range p.GetBuiltCities() {
+			if !hasCityOnCorner(old, c.Position) {
+				anims = append(anims, newCityDrop(c.Position, p.Color))
+			}
+		}
+	}
+
+	if before.Robber.Position != after.Robber.Position {
+		anims = append(anims, newRobberSlide(before.Robber.Position, after.Robber.Position))
+	}
+
+	return anims
+}
+
+func hasCityOnCorner(p game.Player, corner game.TileCorner) bool {
+	for _, c := range p.GetBuiltCities() {
+		if c.Position == corner {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceAnimations lets one symbol fly from every tile that produced for the
+// shown player to the resource bar. They start after delay frames.
+func resourceAnimations(
+	before, after *game.Game,
+	shown game.Color,
+	targets animationTargets,
+	delay int,
+) []*animation {
+	var player, old game.Player
+	for i, p := range after.GetPlayers() {
+		if p.Color == shown {
+			player, old = p, before.Players[i]
+		}
+	}
+
+	var anims []*animation
+	dice := after.Dice[0] + after.Dice[1]
+	for _, tile := range after.Tiles {
+		if tile.Number != dice || tile.Position == after.Robber.Position {
+			continue
+		}
+		resource := tile.Resource()
+		if player.Resources[resource] <= old.Resources[resource] {
+			continue
+		}
+		x, y, w, h := tileToScreen(tile.Position)
+		fromX, fromY := targets.boardToHUD(x+w/2, y+h/2)
+		for _, corner := range game.AdjacentCornersToTile(tile.Position) {
+			if player.HasBuildingOnCorner(corner) {
+				anim := newFlyingResource(resource, fromX, fromY, targets)
+				anim.delay = delay + 8*len(anims)
+				anims = append(anims, anim)
+			}
+		}
+	}
+	return anims
+}
+
+func newDiceAnimation(dice [2]int, targets animationTargets) *animation {
+	return &animation{
+		kind:   diceAnimation,
+		frames: 40,
+		draw: func(g *graphics, t float64) {
+			// the dice show a new face every few frames and jump less and less
+			// until they show the rolled numbers
+			step := int(t * 10)
+			for i := range dice {
+				face := 1 + (step*5+i*3+dice[i])%6
+				if t > 0.8 {
+					face = dice[i]
+				}
+				jump := int(40 * math.Abs(math.Sin(t*3*math.Pi)) * (1 - t))
+				x, y := targets.dieCenter(i)
+				g.drawImageCenteredAt(dieImage(face), x, y-jump)
+			}
+		},
+	}
+}
+
+func newFlyingResource(r game.Resource, fromX, fromY int, targets animationTargets) *animation {
+	return &animation{
+		kind:   resourceAnimation,
+		frames: 30,
+		draw: func(g *graphics, t float64) {
+			toX, toY := targets.resourceSymbolCenter(r)
+			t = easeInOut(t)
+			g.drawImageCenteredAt(
+				resourceToString(r)+"_symbol",
+				lerp(fromX, toX, t),
+				lerp(fromY, toY, t),
+			)
+		},
+	}
+}
+
+// pieceDropHeight is how far above their places new pieces start falling.
+const pieceDropHeight = 150
+
+func newPieceDrop(x, y int, draw func(g *graphics, x, y int)) *animation {
+	return &animation{
+		kind:    pieceAnimation,
+		frames:  20,
+		onBoard: true,
+		draw: func(g *graphics, t float64) {
+			// falling speeds up like under gravity
+			draw(g, x, lerp(y-pieceDropHeight, y, t*t))
+		},
+	}
+}
+
+func newRoadDrop(edge game.TileEdge, color game.Color) *animation {
+	x, y := edgeToScreen(edge)
+	return newPieceDrop(x, y, func(g *graphics, x, y int) {
+		g.drawRoadAt(x, y, edge, color)
+	})
+}
+
+func newSettlementDrop(corner game.TileCorner, color game.Color) *animation {
+	x, y := cornerToScreen(corner)
+	return newPieceDrop(x, y, func(g *graphics, x, y int) {
+		g.drawSettlementAt(x, y, color)
+	})
+}
+
+func newCityDrop(corner game.TileCorner, color game.Color) *animation {
+	x, y := cornerToScreen(corner)
+	return newPieceDrop(x, y, func(g *graphics, x, y int) {
+		g.drawCityAt(x, y, color)
+	})
+}
+
+func newRobberSlide(from, to game.TilePosition) *animation {
+	fromX, fromY, w, h := tileToScreen(from)
+	toX, toY, _, _ := tileToScreen(to)
+	return &animation{
+		kind:    robberAnimation,
+		frames:  30,
+		onBoard: true,
+		draw: func(g *graphics, t float64) {
+			t = easeInOut(t)
+			g.drawRobber(lerp(fromX, toX, t), lerp(fromY, toY, t), w, h)
+		},
+	}
+}
+
+// easeInOut starts slow, speeds up and slows down again at the end.
+func easeInOut(t float64) float64 {
+	return 0.5 - 0.5*math.Cos(t*math.Pi)
+}
+
+func lerp(from, to int, t float64) int {
+	return from + int(math.Floor(float64(to-from)*t+0.5))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"github.com/gonutz/settlers/game"
+	"testing"
+)
+
+func TestRollingTheDiceTumblesDiceAndFliesResources(t *testing.T) {
+	before := goldenGame()
+	before.State = game.RollingDice
+	after := before.Clone()
+	number := producingNumberAt(t, after, after.Players[0].Settlements[0].Position)
+	after.Dice = [2]int{number - number/2, number / 2}
+	after.DealResources(number)
+	after.State = game.ChoosingNextAction
+
+	red, white := after.Players[0].Color, after.Players[2].Color
+	anims := changeAnimations(before, after, red, noTransform{})
+	if kinds := countKinds(anims); kinds[diceAnimation] != 1 || kinds[resourceAnimation] == 0 {
+		t.Fatalf("want dice and resources to be animated but got %v", kinds)
+	}
+	for _, a := range anims {
+		if a.kind == resourceAnimation && a.delay < anims[0].frames {
+			t.Error("resources should fly after the dice stopped")
+		}
+	}
+
+	// other players do not see red's resources fly
+	if kinds := countKinds(changeAnimations(before, after, white, noTransform{})); kinds[resourceAnimation] != 0 {
+		t.Errorf("white sees red's resources: %v", kinds)
+	}
+}
+
+func TestNewPiecesAndRobberAreAnimated(t *testing.T) {
+	before := goldenGame()
+	after := before.Clone()
+	after.Players[1].Roads[2].Position = game.TileEdge{X: 8, Y: 4}
+	after.Players[2].Settlements[0].Position = game.TileCorner{X: 12, Y: 2}
+	after.Players[0].Cities[1].Position = game.TileCorner{X: 4, Y: 6}
+	after.Robber.Position = game.TilePosition{X: 5, Y: 2}
+	after.Reindex()
+
+	kinds := countKinds(changeAnimations(before, after, game.Red, noTransform{}))
+	if kinds[pieceAnimation] != 3 || kinds[robberAnimation] != 1 {
+		t.Errorf("want 3 pieces and the robber to be animated but got %v", kinds)
+	}
+	if kinds := countKinds(changeAnimations(after, after, game.Red, noTransform{})); len(kinds) != 0 {
+		t.Errorf("nothing changed but got %v", kinds)
+	}
+}
+
+func TestAnimatorShowsOldStateUntilAnimationsAreDone(t *testing.T) {
+	var a animator
+	before, current := goldenGame(), goldenGame()
+	if a.busy() || a.shownGame(current) != current {
+		t.Fatal("new animator should be idle")
+	}
+
+	a.play(before,
+		&animation{kind: pieceAnimation, frames: 2},
+		&animation{kind: diceAnimation, frames: 1, delay: 2},
+	)
+	for frame := 0; frame < 3; frame++ {
+		if !a.busy() || a.shownGame(current) != before {
+			t.Fatalf("frame %d: animations should still play", frame)
+		}
+		a.update()
+	}
+	if a.busy() || a.shownGame(current) != current {
+		t.Error("animations should be done after all frames")
+	}
+}
+
+// producingNumberAt returns the number of a land tile next to the corner.
+func producingNumberAt(t *testing.T, g *game.Game, corner game.TileCorner) int {
+	for _, pos := range game.AdjacentTilesToCorner(corner) {
+		if tile, ok := g.GetTileAt(pos); ok && tile.Number != 0 && pos != g.Robber.Position {
+			return tile.Number
+		}
+	}
+	t.Fatal("no number next to", corner)
+	return 0
+}
+
+func countKinds(anims []*animation) map[animationKind]int {
+	kinds := make(map[animationKind]int)
+	for _, a := range anims {
+		kinds[a.kind]++
+	}
+	return kinds
+}
+
+// noTransform leaves board coordinates as they are and puts all HUD targets at
+// the origin.
+type noTransform struct{}
+
+func (noTransform) boardToHUD(x, y int) (int, int)                { return x, y }
+func (noTransform) dieCenter(int) (x, y int)                      { return 0, 0 }
+func (noTransform) resourceSymbolCenter(game.Resource) (x, y int) { return 0, 0 }
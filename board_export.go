@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/gonutz/settlers/game"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// exportBoard writes the board with all pieces and the robber to a file. The
+// format is chosen by the file extension, .png or .svg.
+func exportBoard(path string, g *game.Game, images map[string]image.Image) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".png" && ext != ".svg" {
+		return errors.New("unknown image format, use .png or .svg: " + path)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if ext == ".png" {
+		err = png.Encode(file, boardImage(g, images))
+	} else {
+		err = writeBoardSVG(file, g)
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// exportNewBoard writes the board of a new game with three players. It does not
+// need a window so it can be used e.g. on a server.
+func exportNewBoard(path string, seed int) error {
+	atlas, parts, err := loadImages()
+	if err != nil {
+		return err
+	}
+	g := game.New([]game.Color{game.Red, game.Blue, game.White}, seed)
+	return exportBoard(path, g, subImages(atlas, parts))
+}
+
+// boardImage draws the board like it looks in the game, without the menus.
+func boardImage(g *game.Game, images map[string]image.Image) *image.RGBA {
+	dest := image.NewRGBA(image.Rect(0, 0, gameW, gameH))
+	gr := &graphics{images: images, renderer: newSoftwareRenderer(dest, images)}
+	gr.createGameBackground(g)
+	gr.drawBoard(g)
+	return dest
+}
+
+// writeBoardSVG draws the board as a vector graphic. It does not use the game's
+// images, tiles are drawn as colored hexagons with their numbers on top so the
+// board stays crisp at any size.
+func writeBoardSVG(w io.Writer, g *game.Game) error {
+	out := bufio.NewWriter(w)
+	p := func(format string, a ...interface{}) { fmt.Fprintf(out, format+"\n", a...) }
+
+	p(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		gameW, gameH, gameW, gameH)
+	p(`<rect width="100%%" height="100%%" fill="%s"/>`, svgColor(clearColor))
+
+	for _, tile := range g.Tiles {
+		x, y, w, h := tileToScreen(tile.Position)
+		p(`<polygon points="%d,%d %d,%d %d,%d %d,%d %d,%d %d,%d" fill="%s" stroke="black" stroke-width="2"/>`,
+			x+w/2, y,
+			x+w, y+tileSlopeHeight,
+			x+w, y+h-tileSlopeHeight,
+			x+w/2, y+h,
+			x, y+h-tileSlopeHeight,
+			x, y+tileSlopeHeight,
+			terrainSVGColor(tile.Terrain),
+		)
+		cx, cy := x+w/2, y+h/2
+		if tile.Terrain == game.Water && tile.Harbor.Kind != game.NoHarbor {
+			ratio, fill := "2:1", harborSVGColor(tile.Harbor.Kind)
+			if tile.Harbor.Kind == game.ThreeToOneHarbor {
+				ratio = "3:1"
+			}
+			p(`<circle cx="%d" cy="%d" r="35" fill="%s" stroke="black" stroke-width="2"/>`,
+				cx, cy, fill)
+			p(`<text x="%d" y="%d" font-family="serif" font-size="28" text-anchor="middle">%s</text>`,
+				cx, cy+10, ratio)
+		}
+		if tile.Number != 0 {
+			textColor := "black"
+			if tile.Number == 6 || tile.Number == 8 {
+				textColor = "red"
+			}
+			p(`<circle cx="%d" cy="%d" r="35" fill="white" stroke="black" stroke-width="2"/>`,
+				cx, cy)
+			p(`<text x="%d" y="%d" font-family="serif" font-size="40" font-weight="bold" text-anchor="middle" fill="%s">%d</text>`,
+				cx, cy+14, textColor, tile.Number)
+		}
+	}
+
+	for _, player := range g.GetPlayers() {
+		color := svgColor(fullPlayerColor(player.Color))
+		for _, r := range player.GetBuiltRoads() {
+			x1, y1, x2, y2 := edgeEnds(r.Position)
+			p(`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black" stroke-width="16" stroke-linecap="round"/>`,
+				x1, y1, x2, y2)
+			p(`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="10" stroke-linecap="round"/>`,
+				x1, y1, x2, y2, color)
+		}
+	}
+	for _, player := range g.GetPlayers() {
+		color := svgColor(fullPlayerColor(player.Color))
+		for _, s := range player.GetBuiltSettlements() {
+			x, y := cornerToScreen(s.Position)
+			p(`<polygon points="%d,%d %d,%d %d,%d %d,%d %d,%d" fill="%s" stroke="black" stroke-width="2"/>`,
+				x, y-20, x+15, y-5, x+15, y+15, x-15, y+15, x-15, y-5, color)
+		}
+		for _, c := range player.GetBuiltCities() {
+			x, y := cornerToScreen(c.Position)
+			p(`<polygon points="%d,%d %d,%d %d,%d %d,%d %d,%d %d,%d %d,%d" fill="%s" stroke="black" stroke-width="2"/>`,
+				x-10, y-25, x+2, y-13, x+2, y-5, x+22, y-5, x+22, y+18, x-22, y+18, x-22, y-13, color)
+		}
+	}
+
+	x, y, tw, th := tileToScreen(g.Robber.Position)
+	p(`<ellipse cx="%d" cy="%d" rx="18" ry="30" fill="#404040" stroke="black" stroke-width="2"/>`,
+		x+tw/2, y+th/2+10)
+	p(`<circle cx="%d" cy="%d" r="13" fill="#404040" stroke="black" stroke-width="2"/>`,
+		x+tw/2, y+th/2-30)
+
+	p(`</svg>`)
+	return out.Flush()
+}
+
+func svgColor(c [4]float32) string {
+	n := toNRGBA(c)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
+
+func terrainSVGColor(t game.Terrain) string {
+	switch t {
+	case game.Forest:
+		return "#2e7d32"
+	case game.Field:
+		return "#f4e04d"
+	case game.Mountains:
+		return "#9e9e9e"
+	case game.Pasture:
+		return "#8bc34a"
+	case game.Hills:
+		return "#d84315"
+	case game.Desert:
+		return "#e0c090"
+	default: // water
+		return "#6a8ad8"
+	}
+}
+
+func harborSVGColor(h game.HarborKind) string {
+	switch h {
+	case game.WoolHarbor:
+		return terrainSVGColor(game.Pasture)
+	case game.LumberHarbor:
+		return terrainSVGColor(game.Forest)
+	case game.BrickHarbor:
+		return terrainSVGColor(game.Hills)
+	case game.OreHarbor:
+		return terrainSVGColor(game.Mountains)
+	case game.GrainHarbor:
+		return terrainSVGColor(game.Field)
+	default: // 3:1
+		return "white"
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/xml"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportBoardAsSVG(t *testing.T) {
+	var svg strings.Builder
+	g := goldenGame()
+	if err := writeBoardSVG(&svg, g); err != nil {
+		t.Fatal(err)
+	}
+
+	elements := make(map[string]int)
+	decoder := xml.NewDecoder(strings.NewReader(svg.String()))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("invalid SVG:", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			elements[start.Name.Local]++
+		}
+	}
+
+	buildings, roads := 0, 0
+	for _, p := range g.GetPlayers() {
+		buildings += len(p.GetBuiltSettlements()) + len(p.GetBuiltCities())
+		roads += len(p.GetBuiltRoads())
+	}
+	if want := len(g.Tiles) + buildings; elements["polygon"] != want {
+		t.Errorf("want %d tiles and buildings but got %d polygons", want, elements["polygon"])
+	}
+	// every road has a black outline
+	if elements["line"] != 2*roads {
+		t.Errorf("want %d roads but got %d lines", roads, elements["line"])
+	}
+}
+
+func TestExportBoardAsPNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "board.png")
+	if err := exportNewBoard(path, 1); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != gameW || img.Bounds().Dy() != gameH {
+		t.Errorf("image has size %v", img.Bounds().Size())
+	}
+
+	if err := exportNewBoard(filepath.Join(t.TempDir(), "board.jpg"), 1); err == nil {
+		t.Error("unknown format was accepted")
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/settings"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden images in testdata")
+
+const (
+	// maxColorDiff is how much a color channel may differ from the golden image
+	// before the pixel counts as different, small changes are expected e.g.
+	// from rounding in the image blending.
+	maxColorDiff = 8
+	// maxDifferentPixels is how many pixels may differ from the golden image.
+	// It is far less than a road or a settlement so pieces that are drawn in
+	// the wrong place are found.
+	maxDifferentPixels = 100
+)
+
+func TestBoardRendering(t *testing.T) {
+	tests := []struct {
+		name      string
+		buyMenu   menuState
+		resources [game.ResourceCount]int
+		palette   string
+		markers   bool
+	}{
+		{"board", closed, [game.ResourceCount]int{0, 1, 2, 3, 4}, "classic", false},
+		{"board_buy_menu", opened, [game.ResourceCount]int{1, 1, 2, 3, 1}, "classic", false},
+		{"board_markers", opened, [game.ResourceCount]int{1, 1, 2, 3, 1}, "okabeIto", true},
+	}
+	defer func(markers bool) { settings.Settings.PlayerMarkers = markers }(settings.Settings.PlayerMarkers)
+	defer setPalette("classic")
+	for _, test := range tests {
+		setPalette(test.palette)
+		settings.Settings.PlayerMarkers = test.markers
+		g := goldenGame()
+		g.Players[0].Resources = test.resources
+		got := renderBoard(t, g, test.buyMenu)
+		path := filepath.Join("testdata", test.name+".png")
+		if *update {
+			writePNG(t, path, got)
+			continue
+		}
+		want := readPNG(t, path)
+		if diff := countDifferentPixels(want, got); diff > maxDifferentPixels {
+			failed := filepath.Join(os.TempDir(), test.name+"_failed.png")
+			writePNG(t, failed, got)
+			t.Errorf("%s: %d pixels differ from %s, the rendered image is in %s",
+				test.name, diff, path, failed)
+		}
+	}
+}
+
+// goldenGame has a fixed board with pieces of every kind for every player.
+func goldenGame() *game.Game {
+	g := game.New([]game.Color{game.Red, game.Blue, game.White}, 1)
+	g.State = game.ChoosingNextAction
+
+	red := &g.Players[0]
+	red.Settlements[0].Position = game.TileCorner{X: 4, Y: 2}
+	red.Cities[0].Position = game.TileCorner{X: 8, Y: 2}
+	red.Roads[0].Position = game.TileEdge{X: 9, Y: 2}
+	red.Roads[1].Position = game.TileEdge{X: 10, Y: 2}
+	red.Roads[2].Position = game.TileEdge{X: 11, Y: 2}
+
+	blue := &g.Players[1]
+	blue.Settlements[0].Position = game.TileCorner{X: 4, Y: 4}
+	blue.Settlements[1].Position = game.TileCorner{X: 7, Y: 4}
+	blue.Roads[0].Position = game.TileEdge{X: 9, Y: 4}
+	blue.Roads[1].Position = game.TileEdge{X: 12, Y: 3}
+
+	white := &g.Players[2]
+	white.Cities[0].Position = game.TileCorner{X: 6, Y: 5}
+	white.Cities[1].Position = game.TileCorner{X: 9, Y: 3}
+	white.Roads[0].Position = game.TileEdge{X: 13, Y: 5}
+	white.Roads[1].Position = game.TileEdge{X: 14, Y: 5}
+	white.Roads[2].Position = game.TileEdge{X: 15, Y: 4}
+
+	g.Reindex()
+	return g
+}
+
+// renderBoard draws what the current player sees during the game.
+func renderBoard(t *testing.T, g *game.Game, buyMenu menuState) *image.RGBA {
+	dest := image.NewRGBA(screenBounds)
+	gr, err := newSoftwareGraphics(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := &gameUI{game: g, graphics: gr, camera: newCamera()}
+	ui.hud = newHUD(gr, ui)
+	if buyMenu == opened {
+		ui.hud.buyMenu.state = opened
+		ui.hud.buyMenu.xOffset = ui.hud.buyMenu.right
+	}
+	if err := ui.init(); err != nil {
+		t.Fatal(err)
+	}
+	ui.drawBaseGame()
+	ui.hud.buyMenu.draw(gr)
+	ui.hud.resources.draw(gr)
+	return dest
+}
+
+func countDifferentPixels(a, b *image.RGBA) int {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return a.Bounds().Dx() * a.Bounds().Dy()
+	}
+	different := 0
+	for i := 0; i < len(a.Pix); i += 4 {
+		for c := i; c < i+4; c++ {
+			d := int(a.Pix[c]) - int(b.Pix[c])
+			if d < -maxColorDiff || d > maxColorDiff {
+				different++
+				break
+			}
+		}
+	}
+	return different
+}
+
+func readPNG(t *testing.T, path string) *image.RGBA {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err, "(run the test with -update to create it)")
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			rgba.Set(x, y, img.At(x, y))
+		}
+	}
+	return rgba
+}
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,41 +1,41 @@
 package main
 
-import "github.com/gonutz/settlers/game"
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/lang"
+	"github.com/gonutz/settlers/network"
+	"strconv"
+	"strings"
+)
 
 const (
 	cellW = 70
 	cellH = 70
+	// buyMenuW is the width of the menu without the icon
+	buyMenuW = 500
+	buyMenuH = 4 * cellH
+	buyIconW = 100
+	buyIconH = tileH / 2
 )
 
-func newBuyMenu(g *graphics, gamer gamer) *buyMenu {
-	symbols := []string{"lumber", "brick", "wool", "ore", "grain"}
-	maxHeight := 0
-	for _, symbol := range symbols {
-		_, h := g.imageSize(symbol + "_symbol")
-		if h > maxHeight {
-			maxHeight = h
-		}
-	}
-
-	mainBounds := rect{0, gameH - 3*tileH/2, 500, 4 * cellH}
-	iconBounds := rect{mainBounds.w, 0, 100, tileH / 2}
-	iconBounds.y = mainBounds.y + mainBounds.h - iconBounds.h
+func newBuyMenu(gamer gamer) *buyMenu {
 	return &buyMenu{
-		graphics:   g,
-		gamer:      gamer,
-		mainBounds: mainBounds,
-		iconBounds: iconBounds,
-		xOffset:    -leftBorder - mainBounds.w,
-		left:       -leftBorder - mainBounds.w,
-		right:      -leftBorder,
-		state:      closed,
+		rect:    rect{0, 0, buyMenuW + buyIconW, buyMenuH},
+		gamer:   gamer,
+		xOffset: -buyMenuW,
+		left:    -buyMenuW,
+		right:   0,
+		state:   closed,
 	}
 }
 
-// The menu comes out of the left screen border, at first it is only an icon
-// and when you click that, the whole menu expands to the right.
+// The menu comes out of the left edge of its bounds, at first it is only an
+// icon and when you click that, the whole menu expands to the right. xOffset
+// goes from left, where only the icon shows, to right, where the whole menu
+// shows.
 //
-//main bounds
+//main rect
 //|       |
 //v       v
 //--------+
@@ -44,13 +44,11 @@ func newBuyMenu(g *graphics, gamer gamer) *buyMenu {
 //-----------+
 //        ^  ^
 //        |  |
-//     icon bounds
+//     icon rect
 //
 type buyMenu struct {
-	graphics    *graphics
+	rect
 	gamer       gamer
-	mainBounds  rect
-	iconBounds  rect
 	left, right int
 	xOffset     int
 	state       menuState
@@ -58,6 +56,7 @@ type buyMenu struct {
 
 type gamer interface {
 	Game() *game.Game
+	do(network.Action)
 }
 
 type menuState int
@@ -69,47 +68,107 @@ const (
 	closing
 )
 
-func (m *buyMenu) draw() {
+func (m *buyMenu) bounds() rect          { return m.rect }
+func (m *buyMenu) setBounds(bounds rect) { m.rect = bounds }
+
+// mainRect returns where the menu is right now, it is partly or completely
+// outside the bounds while the menu is not opened.
+func (m *buyMenu) mainRect() rect {
+	return rect{m.x + m.xOffset, m.y, buyMenuW, buyMenuH}
+}
+
+func (m *buyMenu) iconRect() rect {
+	return rect{m.x + m.xOffset + buyMenuW, m.y + buyMenuH - buyIconH, buyIconW, buyIconH}
+}
+
+func (m *buyMenu) draw(g *graphics) {
 	// draw background and icon
-	m.drawRect(m.mainBounds)
-	m.drawRect(m.iconBounds)
-	x, y := m.iconBounds.center()
-	m.graphics.drawImageCenteredAt("hammer_icon", x+m.xOffset, y)
+	main, icon := m.mainRect(), m.iconRect()
+	g.rect(main.x, main.y, main.w, main.h, buyMenuBackColor)
+	g.rect(icon.x, icon.y, icon.w, icon.h, buyMenuBackColor)
+	g.drawImageCenteredAt("hammer_icon", icon.x+icon.w/2, icon.y+icon.h/2)
 
 	// TODO have the arrow?
 	//arrow := "right_arrow"
 	//if m.state == closed || m.state == closing {
 	//arrow = "left_arrow"
 	//}
-	//m.graphics.drawImageCenteredAt(arrow, x+m.xOffset, m.iconBounds.y+m.iconBounds.h-15)
+	//g.drawImageCenteredAt(arrow, icon.x+icon.w/2, icon.y+icon.h-15)
 
 	// draw costs
-	costs := [][]string{
-		[]string{"lumber_symbol", "brick_symbol"},
-		[]string{"lumber_symbol", "brick_symbol", "grain_symbol", "wool_symbol"},
-		[]string{"grain_symbol", "grain_symbol", "ore_symbol", "ore_symbol", "ore_symbol"},
-		[]string{"grain_symbol", "wool_symbol", "ore_symbol"},
-	}
-	for line, cost := range costs {
-		for column, resource := range cost {
-			x := m.mainBounds.x + m.xOffset + column*cellW + cellW/2
-			y := m.mainBounds.y + line*cellH + cellH/2
-			m.graphics.drawImageCenteredAt(resource, x, y)
+	for line, item := range buyItems {
+		for column, resource := range item.cost {
+			x := main.x + column*cellW + cellW/2
+			y := main.y + line*cellH + cellH/2
+			g.drawImageCenteredAt(resourceSymbol(resource), x, y)
 		}
 	}
-	color := colorToString(m.gamer.Game().GetCurrentPlayer().Color)
+	player := m.gamer.Game().GetCurrentPlayer().Color
+	color := colorToString(player)
 	symbols := []string{"road_" + color + "_up", "settlement_" + color, "city_" + color, "card_symbol"}
 	for line, symbol := range symbols {
-		x := m.xOffset + m.mainBounds.w - cellW
-		y := m.mainBounds.y + line*cellH + cellH/2
+		x := main.x + main.w - cellW
+		y := main.y + line*cellH + cellH/2
 		color := [4]float32{1, 1, 1, 1}
+		if symbol != "card_symbol" {
+			color = pieceTint(player)
+		}
 		if !m.canBuyItem(line) {
 			color[3] = 0.3
 		}
-		m.graphics.drawColoredImageCenteredAt(symbol, x, y, color)
+		g.drawColoredImageCenteredAt(symbol, x, y, color)
 	}
 }
 
+var buyMenuBackColor = [4]float32{0.6, 0.4, 0.1, 0.8}
+
+// buyItems are the lines of the menu from top to bottom.
+var buyItems = [4]struct {
+	name, effect lang.Item
+	cost         []game.Resource
+}{
+	{lang.Road, lang.RoadEffect, []game.Resource{game.Lumber, game.Brick}},
+	{lang.Settlement, lang.SettlementEffect,
+		[]game.Resource{game.Lumber, game.Brick, game.Grain, game.Wool}},
+	{lang.City, lang.CityEffect,
+		[]game.Resource{game.Grain, game.Grain, game.Ore, game.Ore, game.Ore}},
+	{lang.DevelopmentCard, lang.DevelopmentCardEffect,
+		[]game.Resource{game.Grain, game.Wool, game.Ore}},
+}
+
+// tooltip explains the line of the opened menu at x,y with its name, what it
+// does and what it costs.
+func (m *buyMenu) tooltip(x, y int) []string {
+	main := m.mainRect()
+	if m.state != opened || !main.contains(x, y) {
+		return nil
+	}
+	item := buyItems[(y-main.y)/cellH]
+	return []string{
+		lang.Get(item.name),
+		lang.Get(item.effect),
+		lang.Get(lang.CostWord) + " " + costText(item.cost),
+	}
+}
+
+// costText lists how many of each resource are in the cost, e.g. "2 grain, 3
+// ore".
+func costText(cost []game.Resource) string {
+	var counts [game.ResourceCount]int
+	var order []game.Resource
+	for _, r := range cost {
+		if counts[r] == 0 {
+			order = append(order, r)
+		}
+		counts[r]++
+	}
+	parts := make([]string, len(order))
+	for i, r := range order {
+		parts[i] = strconv.Itoa(counts[r]) + " " + resourceName(r)
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (m *buyMenu) canBuyItem(index int) bool {
 	game := m.gamer.Game()
 	switch index {
@@ -125,10 +184,6 @@ func (m *buyMenu) canBuyItem(index int) bool {
 	panic("illegal index")
 }
 
-func (m *buyMenu) drawRect(r rect) {
-	m.graphics.rect(r.x+m.xOffset, r.y, r.w, r.h, [4]float32{0.6, 0.4, 0.1, 0.8})
-}
-
 func (m *buyMenu) update() {
 	const speed = 20
 	if m.state == opening {
@@ -147,40 +202,47 @@ func (m *buyMenu) update() {
 	}
 }
 
-func (m *buyMenu) click(x, y int) {
-	if m.iconBounds.moveBy(m.xOffset, 0).contains(x, y) {
+func (m *buyMenu) mouseMovedTo(x, y int) {}
+
+func (m *buyMenu) click(x, y int) (actionID int) {
+	if m.iconRect().contains(x, y) {
 		if m.state == opened || m.state == opening {
 			m.state = closing
 		} else {
 			m.state = opening
 		}
-		return
+		return -1
 	}
 
+	main := m.mainRect()
 	areaW := 2 * cellW
-	left := m.xOffset + m.mainBounds.w - areaW
 	for line := 0; line < 4; line++ {
-		top := m.mainBounds.y + line*cellH
-		area := rect{left, top, areaW, cellH}
+		area := rect{main.x + main.w - areaW, main.y + line*cellH, areaW, cellH}
 		if area.contains(x, y) && m.canBuyItem(line) {
 			m.state = closing
 			m.buyItem(line)
-			return
+			return -1
 		}
 	}
+	return -1
+}
+
+func (m *buyMenu) runeTyped(rune) {}
+
+func (m *buyMenu) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	return -1
 }
 
 func (m *buyMenu) buyItem(index int) {
-	game := m.gamer.Game()
 	switch index {
 	case 0:
-		game.BuyRoad()
+		m.gamer.do(network.Action{Kind: network.BuyRoad})
 	case 1:
-		game.BuySettlement()
+		m.gamer.do(network.Action{Kind: network.BuySettlement})
 	case 2:
-		game.BuyCity()
+		m.gamer.do(network.Action{Kind: network.BuyCity})
 	case 3:
-		game.BuyDevelopmentCard()
+		m.gamer.do(network.Action{Kind: network.BuyDevelopmentCard})
 	default:
 		panic("illegal index")
 	}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/go-gl/gl/v2.1/gl"
+	"math"
 )
 
 const (
@@ -13,27 +14,155 @@ const (
 	gameH        = 7*tileYOffset + tileSlopeHeight
 )
 
-func newCamera() *camera { return &camera{} }
+const (
+	// minUIScale and maxUIScale limit how much bigger than normal the HUD
+	// can be.
+	minUIScale = 1
+	maxUIScale = 1.5
+)
+
+const (
+	maxZoom = 4
+	// zoomStep is how much one step of the mouse wheel zooms in
+	zoomStep = 1.2
+)
 
+// newCamera shows the whole game with the default borders until the window size
+// is known.
+func newCamera() *camera {
+	return &camera{
+		Left:    -leftBorder,
+		Right:   gameW + rightBorder,
+		Top:     -topBorder,
+		Bottom:  gameH + bottomBorder,
+		zoom:    1,
+		uiScale: 1,
+	}
+}
+
+// camera maps between window pixels and game coordinates. Left, Right, Top
+// and Bottom are the game coordinates at the window borders when the whole game
+// fits into the window. The menus and the information around the board are
+// always shown like that, only uiScale times bigger around the window center.
+// The board itself can be zoomed and moved, its view is zoom times smaller and
+// its center is moved by panX, panY.
 type camera struct {
 	WindowWidth, WindowHeight int
 	Left, Right, Top, Bottom  float64
+	zoom                      float64
+	panX, panY                float64
+	uiScale                   float64
+}
+
+// hudBounds returns the HUD area that fills the window. The HUD elements are
+// anchored to its edges.
+func (c *camera) hudBounds() rect {
+	l, r, t, b := c.hudBorders()
+	left, top := int(math.Ceil(l)), int(math.Ceil(t))
+	return rect{left, top, int(r) - left, int(b) - top}
 }
 
+// hudBorders returns the HUD coordinates at the window borders.
+func (c *camera) hudBorders() (left, right, top, bottom float64) {
+	w := (c.Right - c.Left) / c.uiScale
+	h := (c.Bottom - c.Top) / c.uiScale
+	centerX := (c.Left + c.Right) / 2
+	centerY := (c.Top + c.Bottom) / 2
+	return centerX - w/2, centerX + w/2, centerY - h/2, centerY + h/2
+}
+
+// setUIScale makes the HUD the given times bigger, within minUIScale and
+// maxUIScale.
+func (c *camera) setUIScale(scale float64) {
+	c.uiScale = math.Max(minUIScale, math.Min(maxUIScale, scale))
+}
+
+// windowToGame returns the position on the board at the given window pixel.
 func (c *camera) windowToGame(x, y float64) (int, int) {
-	xPercent := x / float64(c.WindowWidth)
-	yPercent := y / float64(c.WindowHeight)
-	relX := c.Left + xPercent*(c.Right-c.Left)
-	relY := c.Top + yPercent*(c.Bottom-c.Top)
+	left, right, top, bottom := c.boardBorders()
+	return windowToView(x, y, c.WindowWidth, c.WindowHeight, left, right, top, bottom)
+}
+
+// windowToHUD returns the position at the given window pixel in the menus and
+// the information around the board, they are not zoomed.
+func (c *camera) windowToHUD(x, y float64) (int, int) {
+	left, right, top, bottom := c.hudBorders()
+	return windowToView(x, y, c.WindowWidth, c.WindowHeight, left, right, top, bottom)
+}
+
+func windowToView(x, y float64, windowW, windowH int, left, right, top, bottom float64) (int, int) {
+	xPercent := x / float64(windowW)
+	yPercent := y / float64(windowH)
+	relX := left + xPercent*(right-left)
+	relY := top + yPercent*(bottom-top)
 	return int(relX + 0.5), int(relY + 0.5)
 }
 
 func (c *camera) gameToWindow(x, y int) (float64, float64) {
-	xPercent := (float64(x) - c.Left) / (c.Right - c.Left)
-	yPercent := (float64(y) - c.Top) / (c.Bottom - c.Top)
+	left, right, top, bottom := c.boardBorders()
+	xPercent := (float64(x) - left) / (right - left)
+	yPercent := (float64(y) - top) / (bottom - top)
 	return xPercent * float64(c.WindowWidth), yPercent * float64(c.WindowHeight)
 }
 
+// boardBorders returns the game coordinates at the window borders for the
+// zoomed board.
+func (c *camera) boardBorders() (left, right, top, bottom float64) {
+	w := (c.Right - c.Left) / c.zoom
+	h := (c.Bottom - c.Top) / c.zoom
+	centerX := (c.Left+c.Right)/2 + c.panX
+	centerY := (c.Top+c.Bottom)/2 + c.panY
+	return centerX - w/2, centerX + w/2, centerY - h/2, centerY + h/2
+}
+
+// zoomAt zooms the board in by the given number of steps, or out for negative
+// steps. The point under the given window pixel stays where it is.
+func (c *camera) zoomAt(windowX, windowY float64, steps float64) {
+	left, right, top, bottom := c.boardBorders()
+	xPercent := windowX / float64(c.WindowWidth)
+	yPercent := windowY / float64(c.WindowHeight)
+	x := left + xPercent*(right-left)
+	y := top + yPercent*(bottom-top)
+
+	c.zoom *= math.Pow(zoomStep, steps)
+	if c.zoom < 1 {
+		c.zoom = 1
+	}
+	if c.zoom > maxZoom {
+		c.zoom = maxZoom
+	}
+
+	// move the view so x,y is at the same window pixel again
+	w := (c.Right - c.Left) / c.zoom
+	h := (c.Bottom - c.Top) / c.zoom
+	c.panX = x - xPercent*w + w/2 - (c.Left+c.Right)/2
+	c.panY = y - yPercent*h + h/2 - (c.Top+c.Bottom)/2
+	c.clampPan()
+}
+
+// moveBy moves the board by the given number of window pixels.
+func (c *camera) moveBy(dx, dy float64) {
+	left, right, top, bottom := c.boardBorders()
+	c.panX -= dx * (right - left) / float64(c.WindowWidth)
+	c.panY -= dy * (bottom - top) / float64(c.WindowHeight)
+	c.clampPan()
+}
+
+// clampPan keeps the view of the board inside the area that is visible when
+// not zoomed in.
+func (c *camera) clampPan() {
+	maxX := (c.Right - c.Left) * (1 - 1/c.zoom) / 2
+	maxY := (c.Bottom - c.Top) * (1 - 1/c.zoom) / 2
+	c.panX = math.Max(-maxX, math.Min(maxX, c.panX))
+	c.panY = math.Max(-maxY, math.Min(maxY, c.panY))
+}
+
+// resetView shows the whole board again.
+func (c *camera) resetView() {
+	c.zoom = 1
+	c.panX, c.panY = 0, 0
+}
+
 func (cam *camera) windowSizeChangedTo(width, height int) {
 	gl.Viewport(0, 0, int32(width), int32(height))
 	cam.WindowWidth, cam.WindowHeight = width, height
@@ -61,9 +190,24 @@ func (cam *camera) recalcOrthoBorders() {
 	cam.Right = gameW + rightBorder + horizontalBorder
 	cam.Top = -topBorder - verticalBorder
 	cam.Bottom = gameH + bottomBorder + verticalBorder
+	cam.clampPan()
+}
+
+// useBoardView makes the following drawing calls draw the zoomed board.
+func (cam *camera) useBoardView() {
+	left, right, top, bottom := cam.boardBorders()
+	setOrthoProjection(left, right, top, bottom)
+}
+
+// useHUDView makes the following drawing calls draw the unzoomed menus and
+// information around the board.
+func (cam *camera) useHUDView() {
+	setOrthoProjection(cam.hudBorders())
+}
 
+func setOrthoProjection(left, right, top, bottom float64) {
 	gl.MatrixMode(gl.PROJECTION)
 	gl.LoadIdentity()
-	gl.Ortho(cam.Left, cam.Right, cam.Bottom, cam.Top, -1, 1)
+	gl.Ortho(left, right, bottom, top, -1, 1)
 	gl.MatrixMode(gl.MODELVIEW)
 }
@@ -0,0 +1,92 @@
+package main
+
+import "testing"
+
+func TestZoomKeepsPointUnderCursor(t *testing.T) {
+	cam := testCamera()
+	x, y := 300.0, 200.0
+	gameX, gameY := cam.windowToGame(x, y)
+
+	cam.zoomAt(x, y, 3)
+	if cam.zoom <= 1 {
+		t.Fatal("did not zoom in")
+	}
+	if gx, gy := cam.windowToGame(x, y); abs(gx-gameX) > 1 || abs(gy-gameY) > 1 {
+		t.Errorf("point under cursor moved from %v,%v to %v,%v", gameX, gameY, gx, gy)
+	}
+	// menus are not zoomed
+	if hx, hy := cam.windowToHUD(0, 0); hx != int(cam.Left+0.5) || hy != int(cam.Top+0.5) {
+		t.Errorf("menu view changed to %v,%v", hx, hy)
+	}
+}
+
+func TestZoomIsLimited(t *testing.T) {
+	cam := testCamera()
+	cam.zoomAt(10, 10, -5)
+	if cam.zoom != 1 || cam.panX != 0 || cam.panY != 0 {
+		t.Errorf("zoomed out beyond the whole board: %v %v,%v", cam.zoom, cam.panX, cam.panY)
+	}
+	cam.zoomAt(10, 10, 100)
+	if cam.zoom != maxZoom {
+		t.Errorf("zoomed in to %v", cam.zoom)
+	}
+}
+
+func TestBoardStaysInView(t *testing.T) {
+	cam := testCamera()
+	cam.zoomAt(400, 300, 4)
+	cam.moveBy(-100000, 100000)
+	left, right, top, bottom := cam.boardBorders()
+	if right > cam.Right+0.001 || left < cam.Left-0.001 ||
+		top < cam.Top-0.001 || bottom > cam.Bottom+0.001 {
+		t.Errorf("view %v,%v,%v,%v is outside %v,%v,%v,%v",
+			left, right, top, bottom, cam.Left, cam.Right, cam.Top, cam.Bottom)
+	}
+	if right < cam.Right-0.001 || top > cam.Top+0.001 {
+		t.Error("view did not move to the top right")
+	}
+
+	cam.resetView()
+	x, y := cam.windowToGame(123, 456)
+	if hx, hy := cam.windowToHUD(123, 456); x != hx || y != hy {
+		t.Error("reset did not show the whole board")
+	}
+}
+
+func TestDraggingMovesBoardWithMouse(t *testing.T) {
+	cam := testCamera()
+	cam.zoomAt(400, 300, 2)
+	gameX, gameY := cam.windowToGame(400, 300)
+	cam.moveBy(30, -20)
+	if gx, gy := cam.windowToGame(430, 280); abs(gx-gameX) > 1 || abs(gy-gameY) > 1 {
+		t.Errorf("dragged point is at %v,%v instead of %v,%v", gx, gy, gameX, gameY)
+	}
+}
+
+func TestUIScaleEnlargesTheHUDAroundTheCenter(t *testing.T) {
+	cam := testCamera()
+	normal := cam.hudBounds()
+	cx, cy := cam.windowToHUD(400, 300)
+	cam.setUIScale(1.25)
+	scaled := cam.hudBounds()
+	if abs(scaled.w*5-normal.w*4) > 10 || abs(scaled.h*5-normal.h*4) > 10 {
+		t.Errorf("HUD area at scale 1.25 is %v, normally %v", scaled, normal)
+	}
+	if x, y := cam.windowToHUD(400, 300); x != cx || y != cy {
+		t.Errorf("window center moved from %v,%v to %v,%v", cx, cy, x, y)
+	}
+	if x, y := cam.windowToHUD(0, 0); abs(x-scaled.x) > 1 || abs(y-scaled.y) > 1 {
+		t.Errorf("window corner is at %v,%v, not at %v", x, y, scaled)
+	}
+	cam.setUIScale(10)
+	if cam.uiScale != maxUIScale {
+		t.Errorf("UI scale is %v", cam.uiScale)
+	}
+}
+
+func testCamera() *camera {
+	cam := newCamera()
+	cam.WindowWidth, cam.WindowHeight = 800, 600
+	cam.recalcOrthoBorders()
+	return cam
+}
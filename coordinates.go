@@ -1,6 +1,9 @@
 package main
 
-import "github.com/gonutz/settlers/game"
+import (
+	"github.com/gonutz/settlers/game"
+	"math"
+)
 
 const (
 	tileW           = 200
@@ -9,22 +12,35 @@ const (
 	tileYOffset     = tileH - tileSlopeHeight
 )
 
+const (
+	// cornerHitRadius is how far from a corner a click still hits it.
+	cornerHitRadius = 40
+	// edgeHitDistance is how far from an edge a click still hits it. It is
+	// less than half the distance from an edge to the tile center.
+	edgeHitDistance = 35
+)
+
+// screenToCorner returns the corner closest to the screen position, if it is
+// within cornerHitRadius.
 func screenToCorner(x, y int) (corner game.TileCorner, hit bool) {
-	const maxDist = 40
-	tileX := (x + tileW/4) / (tileW / 2)
-	if abs(x-tileX*tileW/2) > maxDist {
-		return
+	// corners are tileW/2 apart horizontally and about tileYOffset
+	// vertically, so the closest one is among the neighbors of this one
+	guessX := (x + tileW/4) / (tileW / 2)
+	guessY := (y + tileYOffset/2) / tileYOffset
+	best := float64(cornerHitRadius)
+	for cx := guessX - 1; cx <= guessX+1; cx++ {
+		for cy := guessY - 1; cy <= guessY+1; cy++ {
+			if cx < 0 || cy < 0 {
+				continue
+			}
+			c := game.TileCorner{X: cx, Y: cy}
+			screenX, screenY := cornerToScreen(c)
+			if d := math.Hypot(float64(x-screenX), float64(y-screenY)); d <= best {
+				best, corner, hit = d, c, true
+			}
+		}
 	}
-	topH := tileH - tileSlopeHeight
-	tileY := (y - tileSlopeHeight/2 + topH/2) / topH
-	nextScreenY := tileY * topH
-	if (tileX%2 == 0 && tileY%2 == 1) || (tileX%2 == 1 && tileY%2 == 0) {
-		nextScreenY += tileSlopeHeight
-	}
-	if abs(y-nextScreenY) > maxDist {
-		return
-	}
-	return game.TileCorner{tileX, tileY}, true
+	return
 }
 
 func abs(x int) int {
@@ -69,14 +85,54 @@ func tileToScreen(p game.TilePosition) (x, y, w, h int) {
 	return p.X * tileW / 2, p.Y * tileYOffset, tileW, tileH
 }
 
+// screenToEdge returns the edge closest to the screen position, if it is
+// within edgeHitDistance.
 func screenToEdge(x, y int) (edge game.TileEdge, hit bool) {
-	edge.X = (x + tileW/8) / (tileW / 4)
-	sectionH := tileH - tileSlopeHeight
-	edge.Y = y / sectionH
-	hit = isValidEdge(edge.X, edge.Y)
+	// edge centers are tileW/4 apart horizontally, the closest edge is among
+	// the neighbors of this one
+	guessX := (x + tileW/8) / (tileW / 4)
+	guessY := y / tileYOffset
+	best := float64(edgeHitDistance)
+	for ex := guessX - 2; ex <= guessX+2; ex++ {
+		for ey := guessY - 1; ey <= guessY+1; ey++ {
+			if ex < 0 || ey < 0 || !isValidEdge(ex, ey) {
+				continue
+			}
+			e := game.TileEdge{X: ex, Y: ey}
+			x1, y1, x2, y2 := edgeEnds(e)
+			if d := distanceToSegment(x, y, x1, y1, x2, y2); d <= best {
+				best, edge, hit = d, e, true
+			}
+		}
+	}
 	return
 }
 
+// edgeEnds returns the screen positions of the two corners that the edge
+// connects.
+func edgeEnds(e game.TileEdge) (x1, y1, x2, y2 int) {
+	x, y := edgeToScreen(e)
+	if isEdgeVertical(e) {
+		dy := (tileH - 2*tileSlopeHeight) / 2
+		return x, y - dy, x, y + dy
+	}
+	dx, dy := tileW/4, tileSlopeHeight/2
+	if isEdgeGoingDown(e) {
+		return x - dx, y - dy, x + dx, y + dy
+	}
+	return x - dx, y + dy, x + dx, y - dy
+}
+
+// distanceToSegment returns the distance from point x,y to the closest point on
+// the line segment from x1,y1 to x2,y2.
+func distanceToSegment(x, y, x1, y1, x2, y2 int) float64 {
+	px, py := float64(x-x1), float64(y-y1)
+	dx, dy := float64(x2-x1), float64(y2-y1)
+	t := (px*dx + py*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(px-t*dx, py-t*dy)
+}
+
 func isValidEdge(x, y int) bool {
 	if y%2 == 0 {
 		return x%4 != 0
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"github.com/gonutz/settlers/game"
+	"testing"
+)
+
+// the board's grid sizes, see package game
+const (
+	testCornerGridW = 15
+	testCornerGridH = 8
+	testEdgeGridW   = 29
+	testEdgeGridH   = 8
+)
+
+func TestCornersRoundTripThroughScreen(t *testing.T) {
+	for x := 0; x < testCornerGridW; x++ {
+		for y := 0; y < testCornerGridH; y++ {
+			corner := game.TileCorner{X: x, Y: y}
+			sx, sy := cornerToScreen(corner)
+			for _, d := range [][2]int{{0, 0}, {25, 0}, {-20, 20}, {0, -30}} {
+				got, hit := screenToCorner(sx+d[0], sy+d[1])
+				if !hit || got != corner {
+					t.Errorf("corner %v offset by %v hit %v (%v)", corner, d, got, hit)
+				}
+			}
+		}
+	}
+}
+
+func TestEdgesRoundTripThroughScreen(t *testing.T) {
+	for x := 0; x < testEdgeGridW; x++ {
+		for y := 0; y < testEdgeGridH; y++ {
+			if !isValidEdge(x, y) {
+				continue
+			}
+			edge := game.TileEdge{X: x, Y: y}
+			sx, sy := edgeToScreen(edge)
+			got, hit := screenToEdge(sx, sy)
+			if !hit || got != edge {
+				t.Errorf("edge %v hit %v (%v)", edge, got, hit)
+			}
+			// a click close to the line but not at its center hits it too
+			x1, y1, x2, y2 := edgeEnds(edge)
+			qx, qy := (3*x1+x2)/4, (3*y1+y2)/4
+			if got, hit := screenToEdge(qx+10, qy); !hit || got != edge {
+				t.Errorf("edge %v near its end hit %v (%v)", edge, got, hit)
+			}
+		}
+	}
+}
+
+func TestTileCentersHitNoCornerOrEdge(t *testing.T) {
+	for x := 1; x < 12; x++ {
+		for y := 1; y < 6; y++ {
+			if isEven(x + y) {
+				continue // not a tile, see game.TilePosition
+			}
+			tx, ty, w, h := tileToScreen(game.TilePosition{X: x, Y: y})
+			cx, cy := tx+w/2, ty+h/2
+			if edge, hit := screenToEdge(cx, cy); hit {
+				t.Errorf("center of tile %v,%v hits edge %v", x, y, edge)
+			}
+			if corner, hit := screenToCorner(cx, cy); hit {
+				t.Errorf("center of tile %v,%v hits corner %v", x, y, corner)
+			}
+		}
+	}
+}
+
+func TestEdgesEndAtCorners(t *testing.T) {
+	for x := 0; x < testEdgeGridW; x++ {
+		for y := 0; y < testEdgeGridH; y++ {
+			if !isValidEdge(x, y) {
+				continue
+			}
+			edge := game.TileEdge{X: x, Y: y}
+			x1, y1, x2, y2 := edgeEnds(edge)
+			for _, end := range [][2]int{{x1, y1}, {x2, y2}} {
+				corner, hit := screenToCorner(end[0], end[1])
+				if !hit {
+					t.Errorf("edge %v ends at %v which is no corner", edge, end)
+					continue
+				}
+				if cx, cy := cornerToScreen(corner); cx != end[0] || cy != end[1] {
+					t.Errorf("edge %v ends at %v but the corner is at %v,%v", edge, end, cx, cy)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/gonutz/settlers/lang"
+)
+
+var (
+	dialogShadeColor = [4]float32{0, 0, 0, 0.5}
+	dialogBackColor  = [4]float32{0.8, 0.6, 0.5, 1}
+	dialogTitleColor = [4]float32{0, 0, 0, 1}
+)
+
+var (
+	dialogPadding       = 30
+	dialogTitleH        = 80
+	dialogButtonW       = 250
+	dialogButtonH       = 80
+	dialogButtonSpacing = 20
+)
+
+// newDialog creates a modal dialog in the center of the screen. It shows the
+// title, the content below it and a button for each answer below that. The
+// content can be any element, e.g. a window with a layout, or nil. Pressing a
+// button closes the dialog and calls done with the button's caption.
+//
+// Enter chooses the focused button or the first one if no button has the
+// focus, Escape chooses the last one, so it should be the answer that cancels.
+func newDialog(title lang.Item, content guiElement, answers []lang.Item, done func(answer lang.Item)) *dialog {
+	d := &dialog{title: title, content: content, answers: answers, done: done,
+		buttonW: dialogButtonW}
+	elems := []guiElement{}
+	if content != nil {
+		elems = append(elems, content)
+	}
+	for i, answer := range answers {
+		b := newButton(answer, rect{0, 0, dialogButtonW, dialogButtonH}, i)
+		d.buttons = append(d.buttons, b)
+		elems = append(elems, b)
+	}
+	d.focus = newFocusManager(elems...)
+	d.layout()
+	return d
+}
+
+// newMessageBox creates a dialog that shows a text and has only an OK button.
+// Long texts are wrapped to fit into the dialog.
+func newMessageBox(title lang.Item, text string, font textSizer, done func()) *dialog {
+	const w = 900
+	var lines []guiElement
+	for _, line := range wrapText(text, w, font) {
+		line := line
+		l := newLabel(rect{0, 0, w, 50}, func() string { return line })
+		l.onBackColor(func() [4]float32 { return dialogBackColor })
+		l.fontColor = &dialogTitleColor
+		lines = append(lines, l)
+	}
+	content := newWindow(rect{}, newTopLeftLayout(), lines...)
+	return newDialog(title, content, []lang.Item{lang.OK}, func(lang.Item) {
+		if done != nil {
+			done()
+		}
+	})
+}
+
+type dialog struct {
+	rect
+	title   lang.Item
+	content guiElement
+	answers []lang.Item
+	buttons []*button
+	buttonW int
+	focus   *focusManager
+	done    func(answer lang.Item)
+	closed  bool
+}
+
+// layout sizes the dialog to fit its content and buttons and centers it on the
+// screen.
+func (d *dialog) layout() {
+	var contentW, contentH int
+	if d.content != nil {
+		b := d.content.bounds()
+		contentW, contentH = b.w, b.h+dialogPadding
+	}
+	buttonsW := len(d.buttons)*(d.buttonW+dialogButtonSpacing) - dialogButtonSpacing
+	w := contentW
+	if buttonsW > w {
+		w = buttonsW
+	}
+	w += 2 * dialogPadding
+	h := dialogTitleH + contentH + dialogButtonH + dialogPadding
+	d.rect = rect{(gameW - w) / 2, (gameH - h) / 2, w, h}
+
+	y := d.y + dialogTitleH
+	if d.content != nil {
+		b := d.content.bounds()
+		d.content.setBounds(rect{d.x + (d.w-b.w)/2, y, b.w, b.h})
+		y += contentH
+	}
+	x := d.x + (d.w-buttonsW)/2
+	for _, b := range d.buttons {
+		b.setBounds(rect{x, y, d.buttonW, dialogButtonH})
+		x += d.buttonW + dialogButtonSpacing
+	}
+}
+
+// fitButtonsTo widens the buttons so the longest answer fits on them.
+func (d *dialog) fitButtonsTo(font textSizer) {
+	for _, answer := range d.answers {
+		if w, _ := font.TextSize(lang.Get(answer)); w+2*dialogPadding > d.buttonW {
+			d.buttonW = w + 2*dialogPadding
+		}
+	}
+	d.layout()
+}
+
+func (d *dialog) bounds() rect { return d.rect }
+
+// setBounds only moves the dialog, its size is given by its content.
+func (d *dialog) setBounds(bounds rect) {
+	dx, dy := bounds.x-d.x, bounds.y-d.y
+	d.rect = d.rect.moveBy(dx, dy)
+	if d.content != nil {
+		d.content.setBounds(d.content.bounds().moveBy(dx, dy))
+	}
+	for _, b := range d.buttons {
+		b.setBounds(b.bounds().moveBy(dx, dy))
+	}
+}
+
+// draw shades everything behind the dialog to show that it has to be answered
+// first.
+func (d *dialog) draw(g *graphics) {
+	g.rect(-leftBorder, -topBorder, gameW+leftBorder+rightBorder,
+		gameH+topBorder+bottomBorder, dialogShadeColor)
+	g.rect(d.x, d.y, d.w, d.h, dialogBackColor)
+	g.writeTextLineCenteredInRect(lang.Get(d.title),
+		rect{d.x, d.y, d.w, dialogTitleH}, dialogTitleColor)
+	d.focus.draw(g)
+}
+
+func (d *dialog) mouseMovedTo(x, y int) { d.focus.mouseMovedTo(x, y) }
+
+func (d *dialog) click(x, y int) (actionID int) {
+	id := d.focus.click(x, y)
+	for i, b := range d.buttons {
+		if id == i && b.contains(x, y) {
+			d.answer(i)
+		}
+	}
+	return -1
+}
+
+func (d *dialog) runeTyped(r rune) { d.focus.runeTyped(r) }
+
+func (d *dialog) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	if key == glfw.KeyEscape && len(d.buttons) > 0 {
+		d.answer(len(d.buttons) - 1)
+		return -1
+	}
+	id := d.focus.keyPressed(key, mods)
+	for i, b := range d.buttons {
+		if id == i && d.focus.focused == b {
+			d.answer(i)
+			return -1
+		}
+	}
+	_, onButton := d.focus.focused.(*button)
+	if (key == glfw.KeyEnter || key == glfw.KeyKPEnter) && !onButton && len(d.buttons) > 0 {
+		d.answer(0)
+	}
+	return -1
+}
+
+func (d *dialog) answer(i int) {
+	if d.closed {
+		return
+	}
+	d.closed = true
+	if d.done != nil {
+		d.done(d.answers[i])
+	}
+}
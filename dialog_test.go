@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/lang"
+	"github.com/gonutz/settlers/network"
+	"image"
+	"testing"
+)
+
+func TestDialogAnswersWithTheChosenButton(t *testing.T) {
+	var answers []lang.Item
+	done := func(answer lang.Item) { answers = append(answers, answer) }
+	yesNo := []lang.Item{lang.Yes, lang.No}
+
+	d := newDialog(lang.QuitQuestion, nil, yesNo, done)
+	no := d.buttons[1].bounds()
+	d.click(no.x+1, no.y+1)
+	d.click(no.x+1, no.y+1)
+
+	d = newDialog(lang.QuitQuestion, nil, yesNo, done)
+	d.keyPressed(glfw.KeyEnter, 0)
+
+	d = newDialog(lang.QuitQuestion, nil, yesNo, done)
+	d.keyPressed(glfw.KeyEscape, 0)
+
+	d = newDialog(lang.QuitQuestion, nil, yesNo, done)
+	d.keyPressed(glfw.KeyTab, glfw.ModShift)
+	d.keyPressed(glfw.KeyTab, glfw.ModShift)
+	d.keyPressed(glfw.KeySpace, 0)
+
+	want := []lang.Item{lang.No, lang.Yes, lang.No, lang.Yes}
+	if len(answers) != len(want) {
+		t.Fatalf("want answers %v but got %v", want, answers)
+	}
+	for i := range want {
+		if answers[i] != want[i] {
+			t.Errorf("answer %d: want %v but got %v", i, want[i], answers[i])
+		}
+	}
+}
+
+func TestDialogContentGetsInput(t *testing.T) {
+	text := newTextBox(lang.Name, rect{0, 0, 500, 80}, testGraphics(), nil)
+	var answer lang.Item = -1
+	d := newDialog(lang.Name, text, []lang.Item{lang.OK, lang.Cancel},
+		func(a lang.Item) { answer = a })
+	if b := d.bounds(); !b.contains(text.x, text.y) || !b.contains(text.x+text.w-1, text.y+text.h-1) {
+		t.Errorf("content %v is not inside the dialog %v", text.rect, b)
+	}
+
+	d.keyPressed(glfw.KeyTab, 0)
+	d.runeTyped('A')
+	if text.text != "A" {
+		t.Errorf("text box in dialog got %q", text.text)
+	}
+	d.keyPressed(glfw.KeyEnter, 0)
+	if answer != lang.OK {
+		t.Errorf("Enter in the text box answered %v", answer)
+	}
+}
+
+func TestOpenDialogBlocksTheGame(t *testing.T) {
+	g := game.New([]game.Color{game.Red, game.Blue, game.White}, 1)
+	g.Start()
+	ui := &gameUI{game: g, camera: newCamera()}
+	ui.confirmQuit()
+
+	ui.KeyDown(glfw.Key2, 0)
+	if g.CurrentPlayer != 0 {
+		t.Error("the game got a key while the dialog was open")
+	}
+	ui.KeyDown(glfw.KeyEscape, 0)
+	if len(ui.dialogs) != 0 {
+		t.Errorf("Escape did not close the dialog")
+	}
+}
+
+func TestEscapeOffersTheBoardExportOnlyDuringAGame(t *testing.T) {
+	g := game.New([]game.Color{game.Red, game.Blue, game.White}, 1)
+	gr, err := newSoftwareGraphics(image.NewRGBA(screenBounds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := &gameUI{game: g, camera: newCamera(), graphics: gr, gui: newFocusManager()}
+	ui.hud = newHUD(gr, ui)
+	ui.KeyDown(glfw.KeyEscape, 0)
+	if d := ui.topDialog(); d == nil || d.title != lang.QuitQuestion {
+		t.Fatal("Escape in the main menu does not ask to quit")
+	}
+	ui.dialogs = nil
+
+	g.Start()
+	ui.KeyDown(glfw.KeyEscape, 0)
+	d := ui.topDialog()
+	if d == nil || len(d.answers) != 3 || d.answers[1] != lang.ExportBoard {
+		t.Fatal("Escape in a game does not offer to export the board")
+	}
+	for _, b := range d.buttons {
+		if w, _ := ui.graphics.TextSize(lang.Get(b.textID)); w > b.w {
+			t.Errorf("%q does not fit on its button", lang.Get(b.textID))
+		}
+	}
+}
+
+type failingRemote struct{ hostedGame }
+
+func (failingRemote) Game() *game.Game                    { return nil }
+func (failingRemote) do(game.Color, network.Action) error { return errors.New("not your turn") }
+
+func TestFailedNetworkActionsAreShown(t *testing.T) {
+	g := game.New([]game.Color{game.Red, game.Blue, game.White}, 1)
+	g.Start()
+	ui := &gameUI{game: g, camera: newCamera(), graphics: testGraphics(), remote: failingRemote{}}
+	ui.do(network.Action{Kind: network.RollDice})
+	if len(ui.dialogs) != 1 {
+		t.Errorf("%d dialogs are open after a failed action", len(ui.dialogs))
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/lang"
+)
+
+// eventText describes the event in the current language. name returns the
+// name of the player with the given color.
+func eventText(e game.Event, name func(game.Color) string) string {
+	player := name(e.Player)
+	switch e.Kind {
+	case game.DiceRolled:
+		return fmt.Sprintf(lang.Get(lang.DiceRolledEvent), player, e.Number)
+	case game.ResourcesReceived:
+		return fmt.Sprintf(lang.Get(lang.ResourcesReceivedEvent),
+			player, e.Number, resourceName(e.Resource))
+	case game.RoadBuilt:
+		return fmt.Sprintf(lang.Get(lang.RoadBuiltEvent), player)
+	case game.SettlementBuilt:
+		return fmt.Sprintf(lang.Get(lang.SettlementBuiltEvent), player)
+	case game.CityBuilt:
+		return fmt.Sprintf(lang.Get(lang.CityBuiltEvent), player)
+	case game.DevelopmentCardBought:
+		return fmt.Sprintf(lang.Get(lang.DevelopmentCardBoughtEvent), player)
+	default:
+		return ""
+	}
+}
+
+func resourceName(r game.Resource) string {
+	return lang.Get(lang.Lumber + lang.Item(r))
+}
+
+// newEventLog creates the log that lists what happened in the game. The HUD
+// puts it in the bottom-left corner.
+func newEventLog(g *graphics, ui *gameUI) *textLog {
+	const w, h = 540, 4*textLogLineH + 2*textLogMargin
+	// while animations play, their events are not shown yet
+	events := func() []game.Event { return ui.animations.shownGame(ui.game).Events }
+	return newTextLog(rect{0, 0, w, h}, g,
+		func() int { return len(events()) },
+		func(i int) string { return eventText(events()[i], ui.playerName) },
+	)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/lang"
+	"image"
+	"testing"
+)
+
+func TestEventsAreDescribedInTheCurrentLanguage(t *testing.T) {
+	defer func(l lang.Language) { lang.CurrentLanguage = l }(lang.CurrentLanguage)
+	name := func(game.Color) string { return "Anna" }
+	e := game.Event{Kind: game.ResourcesReceived, Player: game.Red, Number: 2, Resource: game.Ore}
+
+	lang.CurrentLanguage = lang.English
+	if got := eventText(e, name); got != "Anna received 2 ore" {
+		t.Errorf("English: %q", got)
+	}
+	lang.CurrentLanguage = lang.German
+	if got := eventText(e, name); got != "Anna erhält 2 Erz" {
+		t.Errorf("German: %q", got)
+	}
+}
+
+func TestGameEventsShowUpInTheLog(t *testing.T) {
+	g := game.New([]game.Color{game.Red, game.Blue, game.White}, 1)
+	g.Start()
+	corner := game.TileCorner{X: 4, Y: 2}
+	if !g.CanBuildSettlementAt(corner) {
+		t.Fatal("cannot build first settlement")
+	}
+	g.BuildSettlement(corner)
+	ui := &gameUI{game: g}
+	log := newEventLog(&graphics{renderer: newSoftwareRenderer(image.NewRGBA(screenBounds), nil)}, ui)
+	if log.count() != 1 || log.line(0) != eventText(g.Events[0], ui.playerName) {
+		t.Errorf("log shows %d lines for %v", log.count(), g.Events)
+	}
+}
+
+func TestTextLogWrapsAndScrolls(t *testing.T) {
+	lines := []string{"one", "two three four five six seven", "eight"}
+	font := softwareRenderer{}
+	charW, _ := font.TextSize("x")
+	// the lines are wrapped after about 12 characters
+	log := newTextLog(rect{0, 0, 12*charW + 3*textLogMargin, 2*textLogLineH + 2*textLogMargin},
+		&font, func() int { return len(lines) }, func(i int) string { return lines[i] })
+
+	wrapped := log.wrappedLines()
+	want := []string{"one", "two three", "four five", "six seven", "eight"}
+	if len(wrapped) != len(want) {
+		t.Fatalf("want %q but got %q", want, wrapped)
+	}
+	for i := range want {
+		if wrapped[i] != want[i] {
+			t.Errorf("line %d: want %q but got %q", i, want[i], wrapped[i])
+		}
+	}
+
+	log.scrollBy(100)
+	if log.scroll != 3 {
+		t.Errorf("scrolling up stops at the oldest line, scroll is %d", log.scroll)
+	}
+	log.scrollBy(-100)
+	if log.scroll != 0 {
+		t.Errorf("scrolling down stops at the newest line, scroll is %d", log.scroll)
+	}
+}
+
+func TestTextLogOnlyWrapsNewLines(t *testing.T) {
+	defer func(l lang.Language) { lang.CurrentLanguage = l }(lang.CurrentLanguage)
+	lang.CurrentLanguage = lang.English
+	lines := []string{"one", "two"}
+	asked := 0
+	log := newTextLog(rect{0, 0, 400, 200}, &softwareRenderer{},
+		func() int { return len(lines) },
+		func(i int) string { asked++; return lines[i] })
+
+	log.wrappedLines()
+	log.wrappedLines()
+	if asked != 2 {
+		t.Errorf("the lines were asked for %d times instead of once each", asked)
+	}
+	lines = append(lines, "three")
+	if wrapped := log.wrappedLines(); len(wrapped) != 3 || asked != 3 {
+		t.Errorf("after adding a line the log has %q and asked %d times", wrapped, asked)
+	}
+	lang.CurrentLanguage = lang.German
+	log.wrappedLines()
+	if asked != 6 {
+		t.Errorf("changing the language did not wrap the lines again")
+	}
+	lines = lines[:1]
+	if wrapped := log.wrappedLines(); len(wrapped) != 1 {
+		t.Errorf("the log still has %q after the lines were replaced", wrapped)
+	}
+}
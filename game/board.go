@@ -0,0 +1,101 @@
+package game
+
+const (
+	tileGridW   = 13
+	tileGridH   = 7
+	cornerGridW = tileGridW + 2
+	cornerGridH = tileGridH + 1
+	edgeGridW   = 2*tileGridW + 3
+	edgeGridH   = tileGridH + 1
+)
+
+// board indexes the tiles and everything the players have built by position.
+// The Players' Settlements, Cities and Roads stay the authority on what is
+// built, the board only mirrors them so that the building checks do not have to
+// scan all players for every corner and edge. It has no pointers so copying a
+// Game copies the board as well.
+type board struct {
+	// tiles holds the index into Game.Tiles plus 1, 0 means there is no tile
+	tiles   [tileGridW][tileGridH]int8
+	corners [cornerGridW][cornerGridH]cornerBuilding
+	// edges holds the index of the player with a road on the edge plus 1, 0
+	// means there is no road
+	edges [edgeGridW][edgeGridH]int8
+}
+
+type cornerBuilding struct {
+	// player is the index of the owning player plus 1, 0 means empty corner
+	player int8
+	city   bool
+}
+
+func (b *board) tileIndex(p TilePosition) int {
+	if p.X < 0 || p.Y < 0 || p.X >= tileGridW || p.Y >= tileGridH {
+		return -1
+	}
+	return int(b.tiles[p.X][p.Y]) - 1
+}
+
+func (b *board) buildingAt(c TileCorner) cornerBuilding {
+	if c.X < 0 || c.Y < 0 || c.X >= cornerGridW || c.Y >= cornerGridH {
+		return cornerBuilding{}
+	}
+	return b.corners[c.X][c.Y]
+}
+
+func (b *board) setBuilding(c TileCorner, player int, city bool) {
+	b.corners[c.X][c.Y] = cornerBuilding{int8(player + 1), city}
+}
+
+// roadOwner returns the index of the player with a road on the given edge or -1
+// if the edge is empty.
+func (b *board) roadOwner(e TileEdge) int {
+	if e.X < 0 || e.Y < 0 || e.X >= edgeGridW || e.Y >= edgeGridH {
+		return -1
+	}
+	return int(b.edges[e.X][e.Y]) - 1
+}
+
+func (b *board) setRoad(e TileEdge, player int) {
+	b.edges[e.X][e.Y] = int8(player + 1)
+}
+
+// Reindex rebuilds the position lookup that the building checks use. All
+// methods that build something keep it up to date, call this only after setting
+// the Players' Settlements, Cities or Roads directly.
+func (g *Game) Reindex() {
+	g.board = board{}
+	for i, tile := range g.Tiles {
+		p := tile.Position
+		g.board.tiles[p.X][p.Y] = int8(i + 1)
+	}
+	for i, p := range g.GetPlayers() {
+		for _, s := range p.GetBuiltSettlements() {
+			g.board.setBuilding(s.Position, i, false)
+		}
+		for _, c := range p.GetBuiltCities() {
+			g.board.setBuilding(c.Position, i, true)
+		}
+		for _, r := range p.GetBuiltRoads() {
+			g.board.setRoad(r.Position, i)
+		}
+	}
+}
+
+// Clone returns a deep copy of the game, including the state of the random
+// number generator. The clone and the original can be played independently and
+// will roll the same dice if given the same moves.
+// The Rolls and Events grow for the whole game so the clone shares them with
+// the original instead of copying them. They are only ever appended to and the
+// clone's slices are capped at their length, so appending to them in the clone
+// makes a copy and never overwrites what the original appends.
+func (g *Game) Clone() *Game {
+	clone := *g
+	if g.rand != nil {
+		rand := *g.rand
+		clone.rand = &rand
+	}
+	clone.Rolls = g.Rolls[:len(g.Rolls):len(g.Rolls)]
+	clone.Events = g.Events[:len(g.Events):len(g.Events)]
+	return &clone
+}
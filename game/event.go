@@ -0,0 +1,43 @@
+package game
+
+// Event is something that happened in the game. The game records them in
+// Game.Events so they can be shown to the players.
+type Event struct {
+	Kind   EventKind
+	Player Color
+	// Number is the rolled number for DiceRolled and the number of cards
+	// for ResourcesReceived
+	Number   int
+	Resource Resource
+}
+
+type EventKind int
+
+const (
+	DiceRolled EventKind = iota
+	ResourcesReceived
+	RoadBuilt
+	SettlementBuilt
+	CityBuilt
+	DevelopmentCardBought
+)
+
+func (g *Game) addEvent(kind EventKind, player Color) {
+	g.Events = append(g.Events, Event{Kind: kind, Player: player})
+}
+
+// giveResources adds the resources to the player's hand and records what the
+// player received, one event per kind of resource.
+func (g *Game) giveResources(player *Player, resources [ResourceCount]int) {
+	for r, n := range resources {
+		if n > 0 {
+			player.Resources[r] += n
+			g.Events = append(g.Events, Event{
+				Kind:     ResourcesReceived,
+				Player:   player.Color,
+				Number:   n,
+				Resource: Resource(r),
+			})
+		}
+	}
+}
@@ -20,8 +20,13 @@ type Game struct {
 	DevelopmentCards [25]DevelopmentCard
 	CardsDealt       int
 	Dice             [2]int
+	// Rolls are all rolls of the dice so far, the last one is Dice.
+	Rolls []Roll
+	// Events is everything that happened in the game so far, the newest last.
+	Events []Event
 	// seed is for random number generation
-	rand *randomNumberGenerator
+	rand  *randomNumberGenerator
+	board board
 }
 
 type State int
@@ -46,6 +51,30 @@ type Tile struct {
 	Harbor   Harbor
 }
 
+// Pips returns in how many of the 36 possible rolls of two dice the number
+// comes up. Numbers that are never rolled, like 0 for the desert, have 0 pips.
+func Pips(number int) int {
+	if number < 2 || number > 12 || number == 7 {
+		return 0
+	}
+	if number < 7 {
+		return number - 1
+	}
+	return 13 - number
+}
+
+// CornerPips returns the pips of all land tiles around the corner, the higher
+// it is the more resources a settlement there produces on average.
+func (g *Game) CornerPips(c TileCorner) int {
+	pips := 0
+	for _, pos := range AdjacentTilesToCorner(c) {
+		if tile, ok := g.GetTileAt(pos); ok && tile.Terrain != Water {
+			pips += Pips(tile.Number)
+		}
+	}
+	return pips
+}
+
 // TilePosition's coordinates will always add up to an odd number. The top-most
 // horizontal row has y=0 and the left-most, only half visible, tile has x=-1 so
 // the first full visible tile in that row is x=1.
@@ -152,6 +181,35 @@ type Player struct {
 	Resources      [ResourceCount]int
 	HasLongestRoad bool
 	HasLargestArmy bool
+
+	// HiddenCards are cards in the player's hand of which the kind is not
+	// known, e.g. the resources of other players in a network game.
+	HiddenCards int
+	// PlayedKnights counts the knight cards that the player has played, the
+	// largest army goes to the player with the most of them.
+	PlayedKnights int
+}
+
+// CardCount returns the number of cards in the player's hand.
+func (p Player) CardCount() int {
+	n := p.HiddenCards
+	for _, count := range p.Resources {
+		n += count
+	}
+	return n
+}
+
+// VictoryPoints returns the points that every player can see, that is those for
+// buildings, the longest road and the largest army.
+func (p Player) VictoryPoints() int {
+	points := len(p.GetBuiltSettlements()) + 2*len(p.GetBuiltCities())
+	if p.HasLongestRoad {
+		points += 2
+	}
+	if p.HasLargestArmy {
+		points += 2
+	}
+	return points
 }
 
 type Color int
@@ -367,40 +425,42 @@ func (g *Game) Start() {
 }
 
 func (g *Game) randomizePlayerOrder() {
+	// repeatedly pick one of the remaining players and move it to the front of
+	// them, this keeps the order of the players that were not yet picked
 	players := g.GetPlayers()
-	var order []Player
-	for len(players) > 0 {
-		index := g.rand.next() % len(players)
-		order = append(order, players[index])
-		players = append(players[:index], players[index+1:]...)
-	}
-	for i, p := range order {
-		g.Players[i] = p
-	}
+	for i := range players {
+		index := i + g.rand.next()%(len(players)-i)
+		picked := players[index]
+		copy(players[i+1:index+1], players[i:index])
+		players[i] = picked
+	}
+	// the player indices changed so the board has to be updated
+	g.Reindex()
 }
 
-// TODO create a way to get to the information who received what resources
-// this is necessary to e.g. animate the resources flying to the players
+// DealResources gives the resources of the tiles with the rolled number to the
+// players at them, what they received is in the ResourcesReceived events.
 func (g *Game) DealResources(dice int) {
+	var gains [4][ResourceCount]int
 	for _, tile := range g.Tiles {
 		if tile.Number == dice && g.Robber.Position != tile.Position {
 			corners := AdjacentCornersToTile(tile.Position)
 			for _, corner := range corners {
-				for playerIndex, p := range g.GetPlayers() {
-					for _, s := range p.GetBuiltSettlements() {
-						if s.Position == corner {
-							g.Players[playerIndex].Resources[tile.Resource()]++
-						}
-					}
-					for _, c := range p.GetBuiltCities() {
-						if c.Position == corner {
-							g.Players[playerIndex].Resources[tile.Resource()] += 2
-						}
-					}
+				b := g.board.buildingAt(corner)
+				if b.player == 0 {
+					continue
+				}
+				if b.city {
+					gains[b.player-1][tile.Resource()] += 2
+				} else {
+					gains[b.player-1][tile.Resource()]++
 				}
 			}
 		}
 	}
+	for i := range g.GetPlayers() {
+		g.giveResources(&g.Players[i], gains[i])
+	}
 }
 
 func (g *Game) GetPlayers() []Player {
@@ -432,15 +492,25 @@ func (p Player) GetBuiltRoads() []Road {
 }
 
 func (g *Game) RemainingSettlements() int {
-	p := g.GetCurrentPlayer()
-	return len(p.Settlements) - len(p.GetBuiltSettlements())
+	return g.GetCurrentPlayer().RemainingSettlements()
 }
 
 func (g *Game) RemainingCities() int {
-	p := g.GetCurrentPlayer()
+	return g.GetCurrentPlayer().RemainingCities()
+}
+
+func (p Player) RemainingSettlements() int {
+	return len(p.Settlements) - len(p.GetBuiltSettlements())
+}
+
+func (p Player) RemainingCities() int {
 	return len(p.Cities) - len(p.GetBuiltCities())
 }
 
+func (p Player) RemainingRoads() int {
+	return len(p.Roads) - len(p.GetBuiltRoads())
+}
+
 func (g *Game) CanPlayerBuildCity() bool {
 	return !g.Players[g.CurrentPlayer].Cities[3].isSet()
 }
@@ -470,6 +540,8 @@ func (g *Game) BuildCity(c TileCorner) {
 			break
 		}
 	}
+	g.board.setBuilding(c, g.CurrentPlayer, true)
+	g.addEvent(CityBuilt, player.Color)
 
 	g.State = ChoosingNextAction
 }
@@ -483,6 +555,8 @@ func (g *Game) BuildRoad(e TileEdge) {
 			break
 		}
 	}
+	g.board.setRoad(e, g.CurrentPlayer)
+	g.addEvent(RoadBuilt, player.Color)
 	if g.State == BuildingFirstRoad {
 		g.State = BuildingFirstSettlement
 		g.CurrentPlayer++
@@ -695,10 +769,8 @@ func AdjacentTilesToTile(p TilePosition) [6]TilePosition {
 }
 
 func (g *Game) GetTileAt(p TilePosition) (Tile, bool) {
-	for _, tile := range g.Tiles {
-		if tile.Position == p {
-			return tile, true
-		}
+	if i := g.board.tileIndex(p); i != -1 {
+		return g.Tiles[i], true
 	}
 	return Tile{}, false
 }
@@ -713,22 +785,24 @@ func (g *Game) BuildSettlement(c TileCorner) {
 			break
 		}
 	}
+	g.board.setBuilding(c, g.CurrentPlayer, false)
+	g.addEvent(SettlementBuilt, player.Color)
 
 	if g.State == BuildingFirstSettlement {
 		g.State = BuildingFirstRoad
 	} else if g.State == BuildingSecondSettlement {
-		// TODO make dealt resources available to the UI
-		player := g.currentPlayerPointer()
 		// deal resources for this settlement
+		var resources [ResourceCount]int
 		tilePositions := AdjacentTilesToCorner(c)
 		for _, tilePosition := range tilePositions {
 			tile, valid := g.GetTileAt(tilePosition)
 			if valid {
 				if resource := tile.Resource(); resource != Nothing {
-					player.Resources[resource]++
+					resources[resource]++
 				}
 			}
 		}
+		g.giveResources(player, resources)
 		g.State = BuildingSecondRoad
 	} else if g.State == BuildingNewSettlement {
 		g.State = ChoosingNextAction
@@ -766,19 +840,15 @@ func (g *Game) canBuildBuildingAt(c TileCorner) bool {
 		return false
 	}
 
-	// check that all adjacent corners have no building on them
-	cornerPositions := AdjacentCornersToCorner(c)
-	for _, player := range g.GetPlayers() {
-		if player.HasBuildingOnCorner(c) {
-			// if there is a building on this corner
+	// check that there is no building on this corner
+	if g.board.buildingAt(c).player != 0 {
+		return false
+	}
+	// or on any corner only one corner away
+	for _, corner := range AdjacentCornersToCorner(c) {
+		if g.board.buildingAt(corner).player != 0 {
 			return false
 		}
-		// or if there is a building only one corner away
-		for _, corner := range cornerPositions {
-			if player.HasBuildingOnCorner(corner) {
-				return false
-			}
-		}
 	}
 
 	return true
@@ -789,26 +859,28 @@ func (g *Game) CanBuildCityAt(c TileCorner) bool {
 		return false
 	}
 
-	player := g.currentPlayerPointer()
-	for _, s := range player.GetBuiltSettlements() {
-		if s.Position == c {
-			return true
-		}
-	}
-
-	return false
+	b := g.board.buildingAt(c)
+	return int(b.player) == g.CurrentPlayer+1 && !b.city
 }
 
 func (g *Game) hasRoadToCorner(c TileCorner) bool {
 	edgePositions := AdjacentEdgesToCorner(c)
 	for _, edge := range edgePositions {
-		if g.GetCurrentPlayer().HasRoadOnEdge(edge) {
+		if g.hasCurrentPlayerRoadOnEdge(edge) {
 			return true
 		}
 	}
 	return false
 }
 
+func (g *Game) hasCurrentPlayerRoadOnEdge(e TileEdge) bool {
+	return g.board.roadOwner(e) == g.CurrentPlayer
+}
+
+func (g *Game) hasCurrentPlayerBuildingOnCorner(c TileCorner) bool {
+	return int(g.board.buildingAt(c).player) == g.CurrentPlayer+1
+}
+
 func (p Player) HasBuildingOnCorner(corner TileCorner) bool {
 	for _, s := range p.GetBuiltSettlements() {
 		if s.Position == corner {
@@ -863,10 +935,8 @@ func (g *Game) CanBuildRoadAt(edge TileEdge) bool {
 	}
 
 	// can't build here if there already is a road on this edge
-	for _, p := range g.GetPlayers() {
-		if p.HasRoadOnEdge(edge) {
-			return false
-		}
+	if g.board.roadOwner(edge) != -1 {
+		return false
 	}
 
 	// can only build if at least one adjacent tile is land
@@ -879,7 +949,7 @@ func (g *Game) CanBuildRoadAt(edge TileEdge) bool {
 	hasBuildingNextToIt := false
 	var buildingCorner TileCorner
 	for _, corner := range AdjacentCornersToEdge(edge) {
-		if g.GetCurrentPlayer().HasBuildingOnCorner(corner) {
+		if g.hasCurrentPlayerBuildingOnCorner(corner) {
 			hasBuildingNextToIt = true
 			buildingCorner = corner
 			break
@@ -898,7 +968,7 @@ func (g *Game) CanBuildRoadAt(edge TileEdge) bool {
 		// has to build adjacent to the other settlement.
 		if g.State == BuildingSecondRoad {
 			for _, edge := range AdjacentEdgesToCorner(buildingCorner) {
-				if g.GetCurrentPlayer().HasRoadOnEdge(edge) {
+				if g.hasCurrentPlayerRoadOnEdge(edge) {
 					return false
 				}
 			}
@@ -910,7 +980,7 @@ func (g *Game) CanBuildRoadAt(edge TileEdge) bool {
 		}
 		// can also build if there is a road adjacent to the edge
 		for _, e := range AdjacentEdgesToEdge(edge) {
-			if g.GetCurrentPlayer().HasRoadOnEdge(e) {
+			if g.hasCurrentPlayerRoadOnEdge(e) {
 				return true
 			}
 		}
@@ -919,8 +989,7 @@ func (g *Game) CanBuildRoadAt(edge TileEdge) bool {
 }
 
 func (g *Game) RemainingRoads() int {
-	p := g.GetCurrentPlayer()
-	return len(p.Roads) - len(p.GetBuiltRoads())
+	return g.GetCurrentPlayer().RemainingRoads()
 }
 
 func (g *Game) isLand(p TilePosition) bool {
@@ -1011,6 +1080,7 @@ func (g *Game) BuyDevelopmentCard() {
 	// TODO deal card
 	_ = g.DevelopmentCards[g.CardsDealt]
 	g.CardsDealt++
+	g.addEvent(DevelopmentCardBought, player.Color)
 
 	g.State = ChoosingNextAction
 }
@@ -1022,6 +1092,16 @@ func (g *Game) currentPlayerPointer() *Player {
 func (g *Game) RollTheDice() {
 	g.Dice[0] = 1 + g.rand.next()%6
 	g.Dice[1] = 1 + g.rand.next()%6
+	g.Rolls = append(g.Rolls, Roll{
+		Dice:   g.Dice,
+		Player: g.GetCurrentPlayer().Color,
+		Robber: g.Robber.Position,
+	})
+	g.Events = append(g.Events, Event{
+		Kind:   DiceRolled,
+		Player: g.GetCurrentPlayer().Color,
+		Number: g.Dice[0] + g.Dice[1],
+	})
 	g.DealResources(g.Dice[0] + g.Dice[1])
 	g.State = ChoosingNextAction
 }
@@ -88,3 +88,321 @@ func checkAdjacentTilesToEdge(t *testing.T, edge TileEdge, t1, t2 TilePosition)
 		t.Errorf("for edge %v expected %v %v but was %v %v", edge, t1, t2, tiles[0], tiles[1])
 	}
 }
+
+func TestCloneIsIndependentOfOriginal(t *testing.T) {
+	g := New([]Color{Red, Blue, White}, 5)
+	g.Start()
+	playSetupPhase(g)
+
+	clone := g.Clone()
+	clone.Players[0].Resources[Ore] = 99
+	clone.BuyRoad()
+	if g.Players[0].Resources[Ore] == 99 || g.State == BuildingNewRoad {
+		t.Error("changing the clone changed the original")
+	}
+
+	clone = g.Clone()
+	g.RollTheDice()
+	clone.RollTheDice()
+	if g.Dice != clone.Dice {
+		t.Errorf("clone rolled %v but original rolled %v", clone.Dice, g.Dice)
+	}
+	if g.Players != clone.Players {
+		t.Error("clone and original dealt different resources")
+	}
+}
+
+func TestCloneSharesTheHistoryWithoutMixingItUp(t *testing.T) {
+	g := New([]Color{Red, Blue, White}, 5)
+	g.Start()
+	playSetupPhase(g)
+	// leave room so appending to the original does not move its events
+	g.Events = append(make([]Event, 0, 2*len(g.Events)+10), g.Events...)
+
+	clone := g.Clone()
+	if &clone.Events[0] != &g.Events[0] {
+		t.Error("the clone copied the events")
+	}
+	n := len(g.Events)
+	clone.Events = append(clone.Events, Event{Kind: RoadBuilt, Player: Blue})
+	g.Events = append(g.Events, Event{Kind: CityBuilt, Player: Red})
+	if g.Events[n].Kind != CityBuilt || clone.Events[n].Kind != RoadBuilt {
+		t.Error("the clone and the original overwrote each other's events")
+	}
+}
+
+func TestGetTileAt(t *testing.T) {
+	g := New([]Color{Red, Blue, White}, 0)
+	for _, want := range g.Tiles {
+		tile, ok := g.GetTileAt(want.Position)
+		if !ok || tile != want {
+			t.Errorf("tile at %v: wanted %v got %v %v", want.Position, want, tile, ok)
+		}
+	}
+	for _, p := range []TilePosition{{-1, 0}, {0, 0}, {4, 3 + 7}, {13, 3}} {
+		if _, ok := g.GetTileAt(p); ok {
+			t.Errorf("there should be no tile at %v", p)
+		}
+	}
+}
+
+func TestBuildingChecksFollowDirectlySetPieces(t *testing.T) {
+	g := New([]Color{Red, Blue, White}, 0)
+	g.Start()
+	corner := TileCorner{4, 2}
+	if !g.CanBuildSettlementAt(corner) {
+		t.Fatal("corner should be free")
+	}
+	g.Players[1].Settlements[0].Position = corner
+	g.Reindex()
+	if g.CanBuildSettlementAt(corner) {
+		t.Error("corner is taken after reindexing")
+	}
+	for _, c := range AdjacentCornersToCorner(corner) {
+		if g.CanBuildSettlementAt(c) {
+			t.Errorf("corner %v is next to a settlement", c)
+		}
+	}
+}
+
+func TestPipsCountTheWaysToRollANumber(t *testing.T) {
+	ways := make(map[int]int)
+	for a := 1; a <= 6; a++ {
+		for b := 1; b <= 6; b++ {
+			ways[a+b]++
+		}
+	}
+	ways[7] = 0 // the robber does not produce anything
+	for n := 0; n <= 13; n++ {
+		if got := Pips(n); got != ways[n] {
+			t.Errorf("%d: want %d pips but got %d", n, ways[n], got)
+		}
+	}
+}
+
+func TestPlayerInfoCountsPublicPointsAndAllCards(t *testing.T) {
+	p := Player{
+		Resources:      [ResourceCount]int{1, 0, 2, 0, 3},
+		HiddenCards:    4,
+		HasLargestArmy: true,
+	}
+	p.Settlements[0].Position = TileCorner{4, 2}
+	p.Settlements[1].Position = TileCorner{6, 2}
+	p.Cities[0].Position = TileCorner{8, 2}
+	p.Roads[0].Position = TileEdge{9, 2}
+
+	if got := p.CardCount(); got != 10 {
+		t.Errorf("want 10 cards but got %d", got)
+	}
+	if got := p.VictoryPoints(); got != 2+2+2 {
+		t.Errorf("want 6 points but got %d", got)
+	}
+	if p.RemainingRoads() != 14 || p.RemainingSettlements() != 3 || p.RemainingCities() != 3 {
+		t.Errorf("wrong remaining pieces %d %d %d",
+			p.RemainingRoads(), p.RemainingSettlements(), p.RemainingCities())
+	}
+}
+
+func TestRollingAndBuildingAreRecordedAsEvents(t *testing.T) {
+	g := New([]Color{Red, Blue, White}, 0)
+	g.Start()
+	playSetupPhase(g)
+	kinds := make(map[EventKind]int)
+	for _, e := range g.Events {
+		kinds[e.Kind]++
+	}
+	if kinds[SettlementBuilt] != 6 || kinds[RoadBuilt] != 6 {
+		t.Errorf("want 6 settlements and roads in the opening but got %v", kinds)
+	}
+
+	before := g.Clone()
+	g.RollTheDice()
+	newEvents := g.Events[len(before.Events):]
+	if len(newEvents) == 0 || newEvents[0].Kind != DiceRolled ||
+		newEvents[0].Number != g.Dice[0]+g.Dice[1] {
+		t.Fatalf("roll was not recorded first: %v", newEvents)
+	}
+	var received [4][ResourceCount]int
+	for _, e := range newEvents[1:] {
+		if e.Kind != ResourcesReceived {
+			t.Errorf("unexpected event %v", e)
+		}
+		for i, p := range g.GetPlayers() {
+			if p.Color == e.Player {
+				received[i][e.Resource] += e.Number
+			}
+		}
+	}
+	for i, p := range g.GetPlayers() {
+		for r := range p.Resources {
+			if got := p.Resources[r] - before.Players[i].Resources[r]; got != received[i][r] {
+				t.Errorf("%v got %d of %v but the events say %d", p.Color, got, Resource(r), received[i][r])
+			}
+		}
+	}
+}
+
+func TestStatisticsCountRollsAndIncome(t *testing.T) {
+	g := New([]Color{Red, Blue, White}, 0)
+	var tile Tile
+	for _, t := range g.Tiles {
+		if t.Number == 6 {
+			tile = t
+		}
+	}
+	elsewhere := TilePosition{-1, -1}
+	g.Rolls = []Roll{
+		{Dice: [2]int{3, 3}, Robber: elsewhere},
+		{Dice: [2]int{1, 5}, Robber: tile.Position},
+		{Dice: [2]int{2, 4}, Robber: elsewhere},
+		{Dice: [2]int{6, 6}, Robber: tile.Position},
+	}
+	counts := g.RollCounts()
+	if counts[6] != 3 || counts[12] != 1 {
+		t.Errorf("want 3 sixes and 1 twelve but got %v", counts)
+	}
+	produced, blocked := g.TileRolls(tile.Position)
+	if produced != 2 || blocked != 1 {
+		t.Errorf("want 2 produced and 1 blocked but got %d and %d", produced, blocked)
+	}
+
+	g.Events = []Event{
+		{Kind: ResourcesReceived, Player: Red, Number: 2, Resource: Ore},
+		{Kind: ResourcesReceived, Player: Blue, Number: 1, Resource: Ore},
+		{Kind: DiceRolled, Player: Red, Number: 8},
+		{Kind: ResourcesReceived, Player: Red, Number: 1, Resource: Ore},
+	}
+	if income := g.Income(Red); income[Ore] != 3 {
+		t.Errorf("want 3 ore for red but got %v", income)
+	}
+}
+
+// playSetupPhase builds the first two settlements and roads for all players,
+// always on the first legal positions.
+func playSetupPhase(g *Game) {
+	for g.State != RollingDice {
+		if !playFirstLegalMove(g) {
+			panic("no legal move in setup phase")
+		}
+	}
+}
+
+func playFirstLegalMove(g *Game) bool {
+	switch g.State {
+	case BuildingFirstSettlement, BuildingSecondSettlement, BuildingNewSettlement:
+		for x := 0; x < cornerGridW; x++ {
+			for y := 0; y < cornerGridH; y++ {
+				if c := (TileCorner{x, y}); g.CanBuildSettlementAt(c) {
+					g.BuildSettlement(c)
+					return true
+				}
+			}
+		}
+	case BuildingFirstRoad, BuildingSecondRoad, BuildingNewRoad:
+		for x := 0; x < edgeGridW; x++ {
+			for y := 0; y < edgeGridH; y++ {
+				if e := (TileEdge{x, y}); g.CanBuildRoadAt(e) {
+					g.BuildRoad(e)
+					return true
+				}
+			}
+		}
+	case BuildingNewCity:
+		for _, s := range g.GetCurrentPlayer().GetBuiltSettlements() {
+			if g.CanBuildCityAt(s.Position) {
+				g.BuildCity(s.Position)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// playMove makes one move for the current player, buying and building whatever
+// is possible and ending the turn otherwise.
+func playMove(g *Game) {
+	switch g.State {
+	case RollingDice:
+		g.RollTheDice()
+	case ChoosingNextAction:
+		if g.CanBuyCity() {
+			g.BuyCity()
+		} else if g.CanBuySettlement() {
+			g.BuySettlement()
+		} else if g.CanBuyRoad() && g.canBuildAnyRoad() {
+			g.BuyRoad()
+		} else {
+			g.NextTurn()
+		}
+	default:
+		if !playFirstLegalMove(g) {
+			panic("no legal move")
+		}
+	}
+}
+
+func (g *Game) canBuildAnyRoad() bool {
+	for x := 0; x < edgeGridW; x++ {
+		for y := 0; y < edgeGridH; y++ {
+			if g.CanBuildRoadAt(TileEdge{x, y}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func BenchmarkClone(b *testing.B) {
+	g := New([]Color{Red, Blue, White, Orange}, 0)
+	g.Start()
+	playSetupPhase(g)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		clonedGame = g.Clone()
+	}
+}
+
+// clonedGame keeps the compiler from optimizing away the cloning.
+var clonedGame *Game
+
+func BenchmarkCanBuildSettlementAt(b *testing.B) {
+	g := New([]Color{Red, Blue, White, Orange}, 0)
+	g.Start()
+	playSetupPhase(g)
+	g.State = BuildingNewSettlement
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.CanBuildSettlementAt(TileCorner{i % cornerGridW, i % cornerGridH})
+	}
+}
+
+func BenchmarkCanBuildRoadAt(b *testing.B) {
+	g := New([]Color{Red, Blue, White, Orange}, 0)
+	g.Start()
+	playSetupPhase(g)
+	g.State = BuildingNewRoad
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.CanBuildRoadAt(TileEdge{i % edgeGridW, i % edgeGridH})
+	}
+}
+
+// BenchmarkSimulation measures whole moves, it has to stay well above 100000
+// moves per second for the AI search to be feasible.
+func BenchmarkSimulation(b *testing.B) {
+	start := New([]Color{Red, Blue, White, Orange}, 0)
+	start.Start()
+	playSetupPhase(start)
+	g := start.Clone()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%1000 == 0 {
+			g = start.Clone()
+		}
+		playMove(g)
+	}
+}
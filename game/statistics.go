@@ -0,0 +1,52 @@
+package game
+
+// Roll is one roll of the dice.
+type Roll struct {
+	Dice   [2]int
+	Player Color
+	// Robber is where the robber stood, the tile there did not produce.
+	Robber TilePosition
+}
+
+func (r Roll) Sum() int { return r.Dice[0] + r.Dice[1] }
+
+// RollCounts returns how often each sum was rolled, indexed by the sum.
+func (g *Game) RollCounts() [13]int {
+	var counts [13]int
+	for _, r := range g.Rolls {
+		counts[r.Sum()]++
+	}
+	return counts
+}
+
+// TileRolls returns how often the tile's number was rolled while the robber was
+// somewhere else, that is how often it produced for the buildings around it,
+// and how often the robber blocked it.
+func (g *Game) TileRolls(p TilePosition) (produced, blocked int) {
+	tile, ok := g.GetTileAt(p)
+	if !ok || tile.Number == 0 {
+		return 0, 0
+	}
+	for _, r := range g.Rolls {
+		if r.Sum() == tile.Number {
+			if r.Robber == p {
+				blocked++
+			} else {
+				produced++
+			}
+		}
+	}
+	return
+}
+
+// Income returns all resources that the player received so far, from the
+// dice and from the second settlement in the opening.
+func (g *Game) Income(player Color) [ResourceCount]int {
+	var income [ResourceCount]int
+	for _, e := range g.Events {
+		if e.Kind == ResourcesReceived && e.Player == player {
+			income[e.Resource] += e.Number
+		}
+	}
+	return income
+}
@@ -1,31 +1,21 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/go-gl/glfw/v3.1/glfw"
 	"github.com/gonutz/settlers/game"
 	"github.com/gonutz/settlers/lang"
+	"github.com/gonutz/settlers/network"
 	"github.com/gonutz/settlers/settings"
+	"math"
 	"math/rand"
+	"net"
+	"path/filepath"
 	"strconv"
 	"time"
 )
 
-const (
-	NewGameOption = iota
-	JoinRemoteGameOption
-	ChooseLanguageOption
-	QuitOption
-	LanguageOKOption
-	ThreePlayersOption
-	FourPlayersOption
-	StartGameOption
-	NewGameBackOption
-
-	LanguageOptionOffset = 1000
-)
-
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
@@ -34,140 +24,35 @@ func NewGameUI(win Window) (*gameUI, error) {
 	if err := settings.Settings.Load(); err != nil {
 		fmt.Println("cannot load last settings:", err)
 	}
+	t, err := loadTheme(settings.Settings.Theme)
+	if err != nil {
+		fmt.Println("cannot load the theme:", err)
+		t = builtInTheme
+	}
+	t.apply()
+	t.applyFont()
+	if err := setPalette(settings.Settings.Palette); err != nil {
+		fmt.Println("cannot set the player colors:", err)
+	}
 
 	graphics, err := newGraphics()
 	if err != nil {
 		return nil, err
 	}
 	g := game.New([]game.Color{game.Red, game.Blue, game.White}, 1)
-
-	// main menu
-	size := func(w, h int) rect { return rect{w: w, h: h} }
-	newGame := newButton(lang.NewGame, size(500, 80), NewGameOption)
-	joinRemoteGame := newButton(lang.JoinRemoteGame, size(500, 80), JoinRemoteGameOption)
-	chooseLanguage := newButton(lang.LanguageWord, size(500, 80), ChooseLanguageOption)
-	quit := newButton(lang.Quit, size(500, 80), QuitOption)
-	mainMenu := newWindow(
-		rect{0, 0, gameW, gameH},
-		newVerticalFlowLayout(20),
-		newGame,
-		joinRemoteGame,
-		chooseLanguage,
-		quit,
-	)
-
-	// language menu
-	var langBoxes []*checkBox
-	for language := lang.Language(0); language < lang.LastLanguage; language++ {
-		action := LanguageOptionOffset + int(language)
-		cb := newCheckBox(lang.Item(language), size(300, 80), action)
-		cb.setChecked(settings.Settings.Language == int(language))
-		langBoxes = append(langBoxes, cb)
-	}
-	languageMenu := newWindow(
-		rect{0, 0, gameW, gameH},
-		newVerticalFlowLayout(0),
-		newCheckBoxGroup(langBoxes...),
-		newSpacer(size(0, 20)),
-		newButton(lang.OK, size(300, 80), LanguageOKOption),
-	)
-	languageMenu.setVisible(false)
-
-	// new game menu
-	threePlayers := newCheckBox(lang.ThreePlayers, size(350, 80), ThreePlayersOption)
-	threePlayers.checked = settings.Settings.PlayerCount == 3
-	fourPlayers := newCheckBox(lang.FourPlayers, size(350, 80), FourPlayersOption)
-	fourPlayers.checked = settings.Settings.PlayerCount == 4
-	var playerMenus [4]*window
-	for i := range playerMenus {
-		playerIndex := i // need to copy this for use in closures
-		nameText := newTextBox(lang.Name, rect{0, 0, 500, 80}, graphics.font)
-		nameText.text = settings.Settings.PlayerNames[i]
-		nameText.onTextChange(func(text string) {
-			settings.Settings.PlayerNames[playerIndex] = text
-		})
-		playHere := newCheckBox(lang.PlayHere, rect{0, 0, 500, 80}, -1)
-		playHere.onCheckChange(func(checked bool) {
-			if checked {
-				settings.Settings.PlayerTypes[playerIndex] = settings.Human
-			}
-		})
-		playHere.checked = settings.Settings.PlayerTypes[i] == settings.Human
-		playAI := newCheckBox(lang.AIPlayer, rect{0, 0, 500, 80}, -1)
-		playAI.onCheckChange(func(checked bool) {
-			if checked {
-				settings.Settings.PlayerTypes[playerIndex] = settings.AI
-			}
-		})
-		playAI.checked = settings.Settings.PlayerTypes[i] == settings.AI
-		ipText := newTextBox(lang.IP, rect{0, 0, 500, 80}, graphics.font)
-		ipText.text = settings.Settings.IPs[i]
-		ipText.onTextChange(func(text string) {
-			settings.Settings.IPs[playerIndex] = text
-		})
-		portText := newTextBox(lang.Port, rect{0, 0, 500, 80}, graphics.font)
-		portText.text = settings.Settings.Ports[i]
-		portText.onTextChange(func(text string) {
-			settings.Settings.Ports[playerIndex] = text
-		})
-		connectButton := newButton(lang.Connect, size(500, 80), -1)
-		playNetwork := newCheckBox(lang.NetworkPlayer, rect{0, 0, 500, 80}, -1)
-		playNetwork.onCheckChange(func(checked bool) {
-			ipText.setEnabled(checked)
-			portText.setEnabled(checked)
-			connectButton.setEnabled(checked)
-			if checked {
-				settings.Settings.PlayerTypes[playerIndex] = settings.NetworkPlayer
-			}
-		})
-		playNetwork.setChecked(settings.Settings.PlayerTypes[i] == settings.NetworkPlayer)
-
-		playerMenus[i] = newWindow(
-			rect{},
-			newVerticalFlowLayout(0),
-			newSpacer(rect{0, 0, 620, 30}),
-			nameText,
-			newCheckBoxGroup(
-				playHere,
-				playAI,
-				playNetwork,
-			),
-			ipText,
-			portText,
-			connectButton,
-			newSpacer(rect{0, 0, 0, 30}),
-		)
-	}
-	var playerTabs [4]*tab
-	for i := range playerTabs {
-		col := game.Color(i)
-		playerTabs[i] = newTab(fullPlayerColor(col), playerMenus[i], i < settings.Settings.PlayerCount)
-	}
-	playersSheet := newTabSheet(60, playerTabs[:]...)
-
-	newGameMenu := newWindow(
-		rect{0, 0, gameW, gameH},
-		newVerticalFlowLayout(20),
-		newCheckBoxGroup(threePlayers, fourPlayers),
-		playersSheet,
-		newButton(lang.StartGame, rect{0, 0, 400, 80}, StartGameOption),
-		newButton(lang.Back, rect{0, 0, 400, 80}, NewGameBackOption),
-	)
-	newGameMenu.setVisible(false)
-
 	ui := &gameUI{
-		game:           g,
-		window:         win,
-		camera:         newCamera(),
-		graphics:       graphics,
-		mainMenu:       mainMenu,
-		newGameMenu:    newGameMenu,
-		languageMenu:   languageMenu,
-		playerTabSheet: playersSheet,
-		lastPlayerTab:  playerTabs[3],
-	}
-	ui.buyMenu = newBuyMenu(graphics, ui)
-	ui.gui = newComposite(ui.mainMenu, ui.newGameMenu, ui.languageMenu)
+		game:     g,
+		window:   win,
+		camera:   newCamera(),
+		graphics: graphics,
+	}
+	ui.camera.setUIScale(settings.Settings.UIScale)
+	menus, err := ui.loadMenus(graphics, win)
+	if err != nil {
+		return nil, err
+	}
+	ui.hud = newHUD(graphics, ui)
+	ui.gui = newFocusManager(menus...)
 	if err := ui.init(); err != nil {
 		return nil, err
 	}
@@ -182,23 +67,410 @@ type gameUI struct {
 	camera         *camera
 	graphics       *graphics
 	mouseX, mouseY float64
-	buyMenu        *buyMenu
-	gui            *composite
-	mainMenu       *window
-	languageMenu   *window
-	newGameMenu    *window
-	playerTabSheet *tabSheet
-	lastPlayerTab  *tab
-	quitting       bool
+	hud            *hud
+	tooltip        tooltip
+	gui            *focusManager
+	// menus are the windows described in menus.json by their names, only
+	// one of them is visible at a time
+	menus map[string]*window
+	// menuHandlers are called for the action IDs of the menu elements
+	menuHandlers    []func()
+	hostStartButton *button
+	playerTabSheet  *tabSheet
+	quitting        bool
+	// remote is nil for games where all players sit at this computer
+	remote     networkGame
+	networkErr error
+	// draggingBoard is true while the board is moved with the right mouse
+	// button
+	draggingBoard bool
+	// browser finds the games in the local network while the join menu is
+	// open, it is nil if that is not possible, e.g. when the port is in use
+	browser *network.Browser
+	// animations show what changed in the game, lastSeen is the game as it
+	// was drawn in the last frame to find these changes
+	animations animator
+	lastSeen   *game.Game
+	// showingStatistics is true while the dice statistics cover the screen,
+	// F2 shows and hides them
+	showingStatistics bool
+	// dialogs are the open modal dialogs, the last one is on top and gets all
+	// input until it is answered
+	dialogs []*dialog
+	// lostConnectionShown is set when the player was told that the connection
+	// to the host is lost for good
+	lostConnectionShown bool
+	// shownRejection is the last error from the host that the player was told
+	// about, the host sends it when it rejects an action
+	shownRejection error
 }
 
 type Window interface {
 	Close()
 	SetTitle(title string)
+	clipboard
 }
 
 func (ui *gameUI) Game() *game.Game { return ui.game }
 
+// do makes the current player do the action. In a network game the action is
+// sent to the host instead, it checks it and sends back the new state.
+func (ui *gameUI) do(a network.Action) {
+	if ui.remote == nil {
+		network.Apply(ui.game, a)
+		return
+	}
+	if err := ui.remote.do(ui.game.GetCurrentPlayer().Color, a); err != nil {
+		if _, hosted := ui.remote.(hostedGame); hosted {
+			// the server on this computer checked the action and rejected it
+			ui.showDialog(newMessageBox(lang.ActionRejected, err.Error(), ui.graphics, nil))
+		} else {
+			ui.showNetworkError(err)
+		}
+	}
+	if g := ui.remote.Game(); g != nil {
+		ui.game = g
+	}
+}
+
+// isLocalTurn returns true if the current player sits at this computer. Only
+// then does the UI accept input for the game.
+func (ui *gameUI) isLocalTurn() bool {
+	return ui.remote == nil ||
+		ui.remote.isLocalPlayer(ui.game.GetCurrentPlayer().Color)
+}
+
+// shownPlayer returns the player whose resources are shown. That is the current
+// player, except in network games where only the hands of the players at this
+// computer may be seen.
+func (ui *gameUI) shownPlayer() game.Player {
+	current := ui.game.GetCurrentPlayer()
+	if ui.isLocalTurn() {
+		return current
+	}
+	for _, p := range ui.game.GetPlayers() {
+		if ui.remote.isLocalPlayer(p.Color) {
+			return p
+		}
+	}
+	return current
+}
+
+func (ui *gameUI) startNewGame() {
+	var colors, networkSeats []game.Color
+	for i := 0; i < settings.Settings.PlayerCount; i++ {
+		color := game.Color(i)
+		colors = append(colors, color)
+		if settings.Settings.PlayerTypes[i] == settings.NetworkPlayer {
+			networkSeats = append(networkSeats, color)
+		}
+	}
+	ui.game = game.New(colors, rand.Int())
+	ui.game = game.New(colors, 0) // TODO remove
+
+	if len(networkSeats) == 0 {
+		ui.init()
+		ui.game.Start()
+		return
+	}
+
+	// wait for the network players before starting the game
+	server, err := network.Listen(":"+settings.Settings.HostPort, networkSeats)
+	ui.networkErr = err
+	if err != nil {
+		ui.showNetworkError(err)
+	}
+	if err == nil {
+		ui.remote = hostedGame{server}
+		ui.updateStandIn()
+		// if the game can not be announced, players can still join by IP
+		server.Announce(hostGameName(), network.BroadcastAddress)
+	}
+	ui.showMenu("host")
+}
+
+func (ui *gameUI) startHostedGame() {
+	host, ok := ui.remote.(hostedGame)
+	if !ok || !allSeatsTaken(host.seats()) {
+		return
+	}
+	ui.game.Start()
+	// the server gets its own copy, it is changed from the network goroutines
+	host.server.Start(ui.game.Clone())
+	ui.showMenu("newGame")
+	ui.init()
+}
+
+// hostGameName is the name under which a hosted game is found in the local
+// network, it is the name of the first player at this computer.
+func hostGameName() string {
+	for i := 0; i < settings.Settings.PlayerCount; i++ {
+		if settings.Settings.PlayerTypes[i] == settings.Human {
+			return settings.Settings.PlayerNames[i]
+		}
+	}
+	return lang.Get(lang.Title)
+}
+
+func (ui *gameUI) startBrowsing() {
+	ui.stopBrowsing()
+	browser, err := network.Browse(network.DiscoveryAddress)
+	if err != nil {
+		fmt.Println("cannot look for games in the local network:", err)
+		return
+	}
+	ui.browser = browser
+}
+
+func (ui *gameUI) stopBrowsing() {
+	if ui.browser != nil {
+		ui.browser.Close()
+		ui.browser = nil
+	}
+}
+
+// newFoundGameList creates a list of the games found in the local network.
+// Selecting one fills in its address, clicking it again or pressing Enter joins
+// it.
+func (ui *gameUI) newFoundGameList(font textSizer, ipText, portText *textBox) guiElement {
+	games := func() []network.GameInfo {
+		if ui.browser == nil {
+			return nil
+		}
+		return ui.browser.Games()
+	}
+	list := newList(rect{0, 0, 900, 3 * tableRowH}, font, func() []string {
+		var items []string
+		for _, g := range games() {
+			if g.Version != network.Version {
+				items = append(items, g.Name+" ("+lang.Get(lang.OtherVersion)+")")
+			} else {
+				items = append(items, fmt.Sprintf("%s (%d %s)", g.Name, g.FreeSeats, lang.Get(lang.FreeSeats)))
+			}
+		}
+		return items
+	})
+	// address returns the address of the game in the given row if it can be
+	// joined
+	address := func(row int) (host, port string, ok bool) {
+		found := games()
+		if row >= len(found) || found[row].Version != network.Version {
+			return "", "", false
+		}
+		host, port, err := net.SplitHostPort(found[row].Address)
+		return host, port, err == nil
+	}
+	list.onSelect(func(row int) {
+		if host, port, ok := address(row); ok {
+			ipText.setText(host)
+			portText.setText(port)
+		}
+	})
+	list.onActivate(func(row int) {
+		if host, port, ok := address(row); ok {
+			ipText.setText(host)
+			portText.setText(port)
+			ui.joinRemoteGame()
+		}
+	})
+	return list
+}
+
+func (ui *gameUI) joinRemoteGame() {
+	ui.closeNetworkGame()
+	address := net.JoinHostPort(settings.Settings.JoinIP, settings.Settings.JoinPort)
+	ui.remote = joinedGame{network.Join(
+		address,
+		settings.Settings.JoinName,
+		settings.Settings.JoinToken,
+	)}
+}
+
+// updateStandIn tells the server whether the computer should play for network
+// players that lost the connection.
+func (ui *gameUI) updateStandIn() {
+	if host, ok := ui.remote.(hostedGame); ok {
+		if settings.Settings.AIForDisconnected {
+			host.server.SetStandIn(network.SimpleStandIn)
+		} else {
+			host.server.SetStandIn(nil)
+		}
+	}
+}
+
+func (ui *gameUI) closeNetworkGame() {
+	if ui.remote != nil {
+		ui.remote.close()
+		ui.remote = nil
+	}
+	ui.networkErr = nil
+	ui.lostConnectionShown = false
+}
+
+// updateNetworkGame takes over the latest state of a network game. A joined
+// game starts when the first state arrives from the host.
+func (ui *gameUI) updateNetworkGame() {
+	if ui.remote == nil {
+		return
+	}
+	ui.hostStartButton.setEnabled(allSeatsTaken(ui.remote.seats()))
+	if joined, ok := ui.remote.(joinedGame); ok {
+		// remember the token right away so the seat is not lost if this
+		// program is closed or crashes
+		if token := joined.client.Token(); token != settings.Settings.JoinToken {
+			settings.Settings.JoinToken = token
+			settings.Settings.Save()
+		}
+	}
+	if joined, ok := ui.remote.(joinedGame); ok && ui.game.State != game.NotStarted &&
+		joined.client.Status() == network.Disconnected && !ui.lostConnectionShown {
+		ui.lostConnectionShown = true
+		err := joined.client.Err()
+		if err == nil {
+			err = errors.New(lang.Get(lang.ConnectionLost))
+		}
+		ui.showNetworkError(err)
+	}
+	if joined, ok := ui.remote.(joinedGame); ok && joined.client.Status() == network.Playing {
+		if err := joined.client.Err(); err != nil && err != ui.shownRejection {
+			ui.shownRejection = err
+			ui.showDialog(newMessageBox(lang.ActionRejected, err.Error(), ui.graphics, nil))
+		}
+	}
+	g := ui.remote.Game()
+	if g == nil {
+		return
+	}
+	starting := ui.game.State == game.NotStarted
+	ui.game = g
+	if starting {
+		ui.stopBrowsing()
+		ui.showMenu("main")
+		ui.init()
+	}
+}
+
+// playerPanels returns the info panels of all players, showing the game as it
+// was before the running animations.
+func (ui *gameUI) playerPanels() []playerPanel {
+	g := ui.animations.shownGame(ui.game)
+	var panels []playerPanel
+	for i, p := range g.GetPlayers() {
+		panels = append(panels, playerPanel{
+			name:    ui.playerName(p.Color),
+			player:  p,
+			current: i == g.CurrentPlayer,
+		})
+	}
+	return panels
+}
+
+// playerName returns the name that the player with the given color chose. In a
+// network game that is the name the player joined with.
+func (ui *gameUI) playerName(color game.Color) string {
+	if ui.remote != nil {
+		for _, seat := range ui.remote.seats() {
+			if seat.Color == color && seat.Name != "" {
+				return seat.Name
+			}
+		}
+	}
+	return settings.Settings.PlayerNames[color]
+}
+
+// waitingForReconnect returns true if it is the turn of a network player that
+// lost the connection.
+func (ui *gameUI) waitingForReconnect() bool {
+	if ui.remote == nil {
+		return false
+	}
+	current := ui.game.GetCurrentPlayer().Color
+	for _, seat := range ui.remote.seats() {
+		if seat.Color == current {
+			return seat.Taken && !seat.Connected
+		}
+	}
+	return false
+}
+
+func allSeatsTaken(seats []network.SeatInfo) bool {
+	for _, seat := range seats {
+		if !seat.Taken {
+			return false
+		}
+	}
+	return true
+}
+
+// connectionStatus describes the state of the network game in the menus.
+func (ui *gameUI) connectionStatus() string {
+	switch remote := ui.remote.(type) {
+	case joinedGame:
+		switch remote.client.Status() {
+		case network.Connecting:
+			return lang.Get(lang.Connecting)
+		case network.InLobby:
+			return lang.Get(lang.WaitingForHost)
+		case network.Reconnecting:
+			return lang.Get(lang.Reconnecting)
+		case network.Disconnected:
+			if err := remote.client.Err(); err != nil {
+				return lang.Get(lang.NotConnected) + ": " + err.Error()
+			}
+			return lang.Get(lang.NotConnected)
+		}
+	case hostedGame:
+		return lang.Get(lang.WaitingForPlayersOnPort) + " " + settings.Settings.HostPort
+	}
+	if ui.networkErr != nil {
+		return lang.Get(lang.NotConnected) + ": " + ui.networkErr.Error()
+	}
+	return ""
+}
+
+// newSeatLabels creates one label for each seat in a network game's lobby,
+// they show who took the seat.
+func (ui *gameUI) newSeatLabels() []guiElement {
+	labels := make([]guiElement, 4)
+	for i := range labels {
+		index := i // need to copy this for use in closures
+		seat := func() (network.SeatInfo, bool) {
+			if ui.remote == nil {
+				return network.SeatInfo{}, false
+			}
+			seats := ui.remote.seats()
+			if index >= len(seats) {
+				return network.SeatInfo{}, false
+			}
+			return seats[index], true
+		}
+		l := newLabel(rect{0, 0, 500, 60}, func() string {
+			s, ok := seat()
+			if !ok {
+				return ""
+			}
+			if !s.Taken {
+				return lang.Get(lang.FreeSeat)
+			}
+			if s.Name == "" {
+				return lang.Get(lang.NetworkPlayer)
+			}
+			return s.Name
+		})
+		l.onBackColor(func() [4]float32 {
+			s, _ := seat()
+			return playerColor(s.Color)
+		})
+		l.fontColor = &seatFontColor
+		labels[i] = l
+	}
+	return labels
+}
+
+// seatFontColor is the color of the names on the seats, they are drawn on the
+// player colors in every theme.
+var seatFontColor = [4]float32{0, 0, 0, 1}
+
 func (ui *gameUI) setLanguage(id lang.Language) {
 	lang.CurrentLanguage = id
 	settings.Settings.Language = int(id)
@@ -206,13 +478,51 @@ func (ui *gameUI) setLanguage(id lang.Language) {
 }
 
 func (ui *gameUI) init() error {
+	ui.camera.resetView()
 	return ui.graphics.createGameBackground(ui.game)
 }
 
-func (ui *gameUI) KeyDown(key glfw.Key) {
-	ui.gui.keyPressed(key)
+func (ui *gameUI) KeyDown(key glfw.Key, mods glfw.ModifierKey) {
+	if d := ui.topDialog(); d != nil {
+		d.keyPressed(key, mods)
+		ui.dropClosedDialogs()
+		return
+	}
+	if ui.game.State == game.NotStarted {
+		if action := ui.gui.keyPressed(key, mods); action != -1 {
+			ui.menuAction(action)
+		}
+	}
 	if key == glfw.KeyEscape {
-		ui.window.Close()
+		if ui.game.State == game.NotStarted {
+			ui.confirmQuit()
+		} else {
+			ui.showGameMenu()
+		}
+	}
+	// the menus only show a placeholder board, there is nothing to export yet
+	if key == glfw.KeyF12 && ui.game.State != game.NotStarted {
+		ui.exportBoard()
+	}
+	if key == glfw.KeyF2 {
+		ui.showingStatistics = !ui.showingStatistics && ui.game.State != game.NotStarted
+	}
+	if ui.game.State != game.NotStarted {
+		ui.hud.eventLog.keyPressed(key, mods)
+		// move the board with the arrow keys, Home shows all of it again
+		const step = 50
+		switch key {
+		case glfw.KeyLeft:
+			ui.camera.moveBy(step, 0)
+		case glfw.KeyRight:
+			ui.camera.moveBy(-step, 0)
+		case glfw.KeyUp:
+			ui.camera.moveBy(0, step)
+		case glfw.KeyDown:
+			ui.camera.moveBy(0, -step)
+		case glfw.KeyHome:
+			ui.camera.resetView()
+		}
 	}
 	if key == glfw.Key1 {
 		ui.game.CurrentPlayer = 0
@@ -229,74 +539,268 @@ func (ui *gameUI) KeyDown(key glfw.Key) {
 }
 
 func (ui *gameUI) MouseButtonDown(button glfw.MouseButton) {
+	if d := ui.topDialog(); d != nil {
+		if button == glfw.MouseButtonLeft {
+			d.click(ui.camera.windowToHUD(ui.mouseX, ui.mouseY))
+			ui.dropClosedDialogs()
+		}
+		return
+	}
+	if button == glfw.MouseButtonRight {
+		ui.draggingBoard = ui.game.State != game.NotStarted
+	}
 	if button != glfw.MouseButtonLeft {
 		return // TODO handle right click when building to undo both buying and build
 	}
 
 	gameX, gameY := ui.camera.windowToGame(ui.mouseX, ui.mouseY)
+	hudX, hudY := ui.camera.windowToHUD(ui.mouseX, ui.mouseY)
 
-	if ui.game.State == game.NotStarted {
-		if action := ui.gui.click(gameX, gameY); action != -1 {
-			switch action {
-			case NewGameOption:
-				ui.mainMenu.visible = false
-				ui.newGameMenu.visible = true
-			case ChooseLanguageOption:
-				ui.mainMenu.visible = false
-				ui.languageMenu.visible = true
-			case QuitOption:
-				ui.window.Close()
-			case LanguageOKOption:
-				ui.languageMenu.visible = false
-				ui.mainMenu.visible = true
-			case NewGameBackOption:
-				ui.newGameMenu.visible = false
-				ui.mainMenu.visible = true
-			case StartGameOption:
-				ui.game = game.New([]game.Color{game.Red, game.Blue, game.White}, rand.Int())
-				ui.game = game.New([]game.Color{game.Red, game.Blue, game.White}, 0) // TODO remove
-				ui.init()
-				ui.game.Start()
-			case ThreePlayersOption:
-				ui.lastPlayerTab.visible = false
-				ui.playerTabSheet.relayout()
-				settings.Settings.PlayerCount = 3
-			case FourPlayersOption:
-				ui.lastPlayerTab.visible = true
-				ui.playerTabSheet.relayout()
-				settings.Settings.PlayerCount = 4
-			}
-			if action >= LanguageOptionOffset {
-				language := lang.Language(action - LanguageOptionOffset)
-				ui.setLanguage(language)
-			}
+	if ui.showingStatistics {
+		// a click anywhere closes the statistics
+		ui.showingStatistics = false
+	} else if ui.game.State == game.NotStarted {
+		if action := ui.gui.click(hudX, hudY); action != -1 {
+			ui.menuAction(action)
 		}
+	} else if ui.hud.playerPanelsWindow.visible && ui.hud.playerPanels.contains(hudX, hudY) {
+		ui.hud.playerPanels.toggle()
+	} else if !ui.isLocalTurn() {
+		// wait for the network players
+	} else if ui.animations.busy() {
+		// let the animations show what happened before going on
 	} else if ui.game.State == game.ChoosingNextAction {
-		ui.buyMenu.click(gameX, gameY)
+		ui.hud.buyMenu.click(hudX, hudY)
 	} else if ui.game.State == game.BuildingFirstSettlement ||
 		ui.game.State == game.BuildingSecondSettlement ||
 		ui.game.State == game.BuildingNewSettlement {
 		corner, hit := screenToCorner(gameX, gameY)
 		if hit && ui.game.CanBuildSettlementAt(corner) {
-			ui.game.BuildSettlement(corner)
+			ui.do(network.Action{Kind: network.BuildSettlement, Corner: corner})
 		}
 	} else if ui.game.State == game.BuildingFirstRoad ||
 		ui.game.State == game.BuildingSecondRoad ||
 		ui.game.State == game.BuildingNewRoad {
 		edge, hit := screenToEdge(gameX, gameY)
 		if hit && ui.game.CanBuildRoadAt(edge) {
-			ui.game.BuildRoad(edge)
+			ui.do(network.Action{Kind: network.BuildRoad, Edge: edge})
 		}
 	} else if ui.game.State == game.BuildingNewCity {
 		corner, hit := screenToCorner(gameX, gameY)
 		if hit && ui.game.CanBuildCityAt(corner) {
-			ui.game.BuildCity(corner)
+			ui.do(network.Action{Kind: network.BuildCity, Corner: corner})
 		}
 	} else if ui.game.State == game.RollingDice {
 		center := rect{gameW/2 - 100, gameH/2 - 50, 200, 100}
-		if center.contains(gameX, gameY) {
-			ui.game.RollTheDice()
+		if center.contains(hudX, hudY) {
+			ui.do(network.Action{Kind: network.RollDice})
+		}
+	}
+}
+
+// menuAction calls the handler of the menu element with the given action ID,
+// no matter if it was clicked or activated with the keyboard.
+func (ui *gameUI) menuAction(action int) {
+	if action >= 0 && action < len(ui.menuHandlers) {
+		ui.menuHandlers[action]()
+	}
+}
+
+// showMenu shows the menu with the given name and hides all others.
+func (ui *gameUI) showMenu(name string) {
+	for n, menu := range ui.menus {
+		menu.setVisible(n == name)
+	}
+}
+
+// loadMenus builds the menus from menus.json with the names bound by
+// menuBuilder.
+func (ui *gameUI) loadMenus(font textSizer, clip clipboard) ([]guiElement, error) {
+	b := ui.menuBuilder(font, clip)
+	menus, byName, err := b.loadMenus(resourcePath("menus.json"))
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	if ui.hostStartButton, ok = b.ids["hostStart"].(*button); !ok {
+		return nil, errors.New("menus: the button hostStart is missing")
+	}
+	if ui.playerTabSheet, ok = b.ids["players"].(*tabSheet); !ok {
+		return nil, errors.New("menus: the player tabs players are missing")
+	}
+	ui.menus = byName
+	ui.menuHandlers = b.handlers
+	return menus, nil
+}
+
+// menuBuilder binds the names that menus.json uses to the UI and the
+// settings.
+func (ui *gameUI) menuBuilder(font textSizer, clip clipboard) *menuBuilder {
+	b := newMenuBuilder(font, clip)
+	b.showMenu = ui.showMenu
+
+	b.actions["quit"] = func() { ui.window.Close() }
+	b.actions["startGame"] = ui.startNewGame
+	b.actions["startHostedGame"] = ui.startHostedGame
+	b.actions["startBrowsing"] = ui.startBrowsing
+	b.actions["joinGame"] = ui.joinRemoteGame
+	b.actions["closeNetworkGame"] = ui.closeNetworkGame
+	b.actions["leaveJoinedGame"] = func() {
+		ui.stopBrowsing()
+		ui.closeNetworkGame()
+	}
+
+	for l := lang.Language(0); l < lang.LastLanguage; l++ {
+		language := l // need to copy this for use in closures
+		b.checks["language"+strconv.Itoa(int(l))] = checkValue{
+			get: func() bool { return settings.Settings.Language == int(language) },
+			set: func(checked bool) {
+				if checked {
+					ui.setLanguage(language)
+				}
+			},
+		}
+	}
+	for bind, name := range map[string]string{
+		"defaultTheme":      "default",
+		"highContrastTheme": "high_contrast",
+	} {
+		name := name // need to copy this for use in closures
+		b.checks[bind] = checkValue{
+			get: func() bool { return settings.Settings.Theme == name },
+			set: func(checked bool) {
+				if checked {
+					ui.setTheme(name)
+				}
+			},
+		}
+	}
+	for bind, name := range map[string]string{
+		"classicColors":  "classic",
+		"okabeItoColors": "okabeIto",
+		"tolColors":      "tol",
+	} {
+		name := name // need to copy this for use in closures
+		b.checks[bind] = checkValue{
+			get: func() bool { return settings.Settings.Palette == name },
+			set: func(checked bool) {
+				if checked && setPalette(name) == nil {
+					settings.Settings.Palette = name
+				}
+			},
+		}
+	}
+	b.checks["playerMarkers"] = checkValue{
+		get: func() bool { return settings.Settings.PlayerMarkers },
+		set: func(checked bool) { settings.Settings.PlayerMarkers = checked },
+	}
+	for _, percent := range []int{100, 115, 130} {
+		scale := float64(percent) / 100
+		b.checks["scale"+strconv.Itoa(percent)] = checkValue{
+			get: func() bool { return math.Abs(settings.Settings.UIScale-scale) < 0.01 },
+			set: func(checked bool) {
+				if checked {
+					ui.setUIScale(scale)
+				}
+			},
+		}
+	}
+	b.checks["threePlayers"] = ui.playerCountValue(3)
+	b.checks["fourPlayers"] = ui.playerCountValue(4)
+	s := settings.Settings
+	for i := 0; i < 4; i++ {
+		n := strconv.Itoa(i)
+		b.texts["playerName"+n] = settingText(&s.PlayerNames[i])
+		b.texts["playerIP"+n] = settingText(&s.IPs[i])
+		b.texts["playerPort"+n] = settingText(&s.Ports[i])
+		b.checks["playHere"+n] = playerTypeValue(i, settings.Human)
+		b.checks["playAI"+n] = playerTypeValue(i, settings.AI)
+		b.checks["playNetwork"+n] = playerTypeValue(i, settings.NetworkPlayer)
+	}
+	b.checks["aiForDisconnected"] = checkValue{
+		get: func() bool { return settings.Settings.AIForDisconnected },
+		set: func(checked bool) {
+			settings.Settings.AIForDisconnected = checked
+			ui.updateStandIn()
+		},
+	}
+	b.texts["joinName"] = settingText(&s.JoinName)
+	b.texts["joinIP"] = settingText(&s.JoinIP)
+	b.texts["joinPort"] = settingText(&s.JoinPort)
+	b.labels["connectionStatus"] = ui.connectionStatus
+	b.validators["host"] = validHostText
+	b.validators["port"] = validPortText
+
+	b.custom["foundGames"] = func(b *menuBuilder) ([]guiElement, error) {
+		ip, ipOK := b.ids["joinIP"].(*textBox)
+		port, portOK := b.ids["joinPort"].(*textBox)
+		if !ipOK || !portOK {
+			return nil, errors.New("found games need the text boxes joinIP and joinPort before them")
 		}
+		return []guiElement{ui.newFoundGameList(font, ip, port)}, nil
+	}
+	b.custom["seatLabels"] = func(*menuBuilder) ([]guiElement, error) {
+		return ui.newSeatLabels(), nil
+	}
+	return b
+}
+
+// playerCountValue checks its box while there are n players, checking it sets
+// the player count to n and shows the tabs of the first n players.
+func (ui *gameUI) playerCountValue(n int) checkValue {
+	return checkValue{
+		get: func() bool { return settings.Settings.PlayerCount == n },
+		set: func(checked bool) {
+			if !checked {
+				return
+			}
+			settings.Settings.PlayerCount = n
+			for i, tab := range ui.playerTabSheet.tabs {
+				tab.visible = i < n
+			}
+			ui.playerTabSheet.relayout()
+		},
+	}
+}
+
+// playerTypeValue checks its box while the player has the given type.
+func playerTypeValue(player int, t settings.PlayerType) checkValue {
+	return checkValue{
+		get: func() bool { return settings.Settings.PlayerTypes[player] == t },
+		set: func(checked bool) {
+			if checked {
+				settings.Settings.PlayerTypes[player] = t
+			}
+		},
+	}
+}
+
+// settingText binds a text box to a text setting.
+func settingText(setting *string) textValue {
+	return textValue{
+		get: func() string { return *setting },
+		set: func(text string) { *setting = text },
+	}
+}
+
+func (ui *gameUI) MouseButtonUp(button glfw.MouseButton) {
+	if button == glfw.MouseButtonRight {
+		ui.draggingBoard = false
+	}
+}
+
+// MouseWheel zooms the board around the mouse cursor. Over the event log and
+// the lists in the menus it scrolls them instead.
+func (ui *gameUI) MouseWheel(steps float64) {
+	hudX, hudY := ui.camera.windowToHUD(ui.mouseX, ui.mouseY)
+	if d := ui.topDialog(); d != nil {
+		d.focus.scrollAt(hudX, hudY, int(steps))
+	} else if ui.game.State == game.NotStarted {
+		ui.gui.scrollAt(hudX, hudY, int(steps))
+	} else if ui.hud.eventLog.contains(hudX, hudY) {
+		ui.hud.eventLog.scrollBy(int(steps))
+	} else {
+		ui.camera.zoomAt(ui.mouseX, ui.mouseY, steps)
 	}
 }
 
@@ -304,45 +808,207 @@ func (ui *gameUI) MouseEntered() {}
 func (ui *gameUI) MouseExited()  { ui.mouseX, ui.mouseY = -10000, -10000 }
 
 func (ui *gameUI) MouseMovedTo(x, y float64) {
-	gameX, gameY := ui.camera.windowToGame(ui.mouseX, ui.mouseY)
-	ui.gui.mouseMovedTo(gameX, gameY)
+	hudX, hudY := ui.camera.windowToHUD(ui.mouseX, ui.mouseY)
+	if d := ui.topDialog(); d != nil {
+		d.mouseMovedTo(hudX, hudY)
+	} else {
+		ui.gui.mouseMovedTo(hudX, hudY)
+	}
+	if ui.draggingBoard {
+		ui.camera.moveBy(x-ui.mouseX, y-ui.mouseY)
+	}
 	ui.mouseX, ui.mouseY = x, y
 }
 
 func (ui *gameUI) RuneTyped(r rune) {
+	if d := ui.topDialog(); d != nil {
+		d.runeTyped(r)
+		return
+	}
 	ui.gui.runeTyped(r)
 }
 
 func (ui *gameUI) WindowSizeChangedTo(width, height int) {
 	ui.camera.windowSizeChangedTo(width, height)
+	ui.relayout()
+}
+
+// relayout fits the HUD and the menus into the window after its size or the UI
+// scale changed.
+func (ui *gameUI) relayout() {
+	area := ui.camera.hudBounds()
+	ui.hud.setBounds(area)
+	for _, menu := range ui.menus {
+		menu.setBounds(area)
+	}
+}
+
+// setTheme switches to the theme with the given name, the font stays the same
+// until the next start.
+func (ui *gameUI) setTheme(name string) {
+	t, err := loadTheme(name)
+	if err != nil {
+		fmt.Println("cannot load the theme:", err)
+		return
+	}
+	t.apply()
+	settings.Settings.Theme = name
+}
+
+func (ui *gameUI) setUIScale(scale float64) {
+	ui.camera.setUIScale(scale)
+	settings.Settings.UIScale = ui.camera.uiScale
+	ui.relayout()
 }
 
 func (ui *gameUI) Draw() {
-	ui.drawBaseGame()
+	ui.updateNetworkGame()
+	ui.animateChanges()
+	ui.animations.update()
+	localTurn := ui.isLocalTurn()
 
-	if ui.game.State > game.BuildingSecondRoad {
-		ui.buyMenu.update()
-		ui.buyMenu.draw()
+	ui.camera.useBoardView()
+	ui.drawBaseGame()
+	if ui.game.State != game.NotStarted && localTurn && !ui.animations.busy() {
+		ui.drawLegalPlacements()
+		ui.drawPieceToBuild()
 	}
 
-	player := ui.game.GetCurrentPlayer()
-	ui.graphics.drawResources(ui.game.GetCurrentPlayer().Resources, playerColor(player.Color))
-	color := player.Color
+	ui.camera.useHUDView()
+	ui.hud.update(ui)
+	ui.hud.draw(ui.graphics)
+	ui.animations.draw(ui.graphics, false)
+
 	if ui.game.State == game.NotStarted {
-		color = game.White
+		ui.gui.draw(ui.graphics)
+	} else if localTurn && ui.game.State == game.RollingDice {
+		const d = 100
+		ui.graphics.rect(gameW/2-2*d, gameH/2-d, 4*d, 2*d, [4]float32{1, 1, 1, 0.8})
+		ui.graphics.drawImageCenteredAt("dice", gameW/2, gameH/2)
+	}
+
+	if ui.showingStatistics {
+		ui.graphics.drawStatistics(ui.game)
+	}
+	for _, d := range ui.dialogs {
+		d.draw(ui.graphics)
 	}
-	ui.graphics.showInstruction(ui.stateInstruction(), color)
 
-	if ui.game.State != game.RollingDice && ui.game.State > game.BuildingSecondRoad {
-		ui.graphics.drawDice(ui.game.Dice)
+	hudX, hudY := ui.camera.windowToHUD(ui.mouseX, ui.mouseY)
+	ui.tooltip.update(hudX, hudY, ui.tooltipAt(ui.mouseX, ui.mouseY))
+	ui.tooltip.draw(ui.graphics, ui.camera.hudBounds())
+}
+
+// showDialog opens the dialog on top of all others. Until it is answered, it
+// gets all input.
+func (ui *gameUI) showDialog(d *dialog) {
+	ui.dialogs = append(ui.dialogs, d)
+}
+
+func (ui *gameUI) topDialog() *dialog {
+	if len(ui.dialogs) == 0 {
+		return nil
+	}
+	return ui.dialogs[len(ui.dialogs)-1]
+}
+
+func (ui *gameUI) dropClosedDialogs() {
+	open := ui.dialogs[:0]
+	for _, d := range ui.dialogs {
+		if !d.closed {
+			open = append(open, d)
+		}
+	}
+	ui.dialogs = open
+}
+
+func (ui *gameUI) confirmQuit() {
+	ui.showDialog(newDialog(lang.QuitQuestion, nil, []lang.Item{lang.Yes, lang.No},
+		func(answer lang.Item) {
+			if answer == lang.Yes {
+				ui.window.Close()
+			}
+		}))
+}
+
+// showGameMenu offers what can be done with a running game besides playing
+// it. The board is only exported from here because the main menu shows a
+// placeholder board.
+func (ui *gameUI) showGameMenu() {
+	d := newDialog(lang.Menu, nil, []lang.Item{lang.Quit, lang.ExportBoard, lang.Back},
+		func(answer lang.Item) {
+			switch answer {
+			case lang.Quit:
+				ui.confirmQuit()
+			case lang.ExportBoard:
+				ui.exportBoard()
+			}
+		})
+	d.fitButtonsTo(ui.graphics)
+	ui.showDialog(d)
+}
+
+func (ui *gameUI) showNetworkError(err error) {
+	ui.showDialog(newMessageBox(lang.NotConnected, err.Error(), ui.graphics, nil))
+}
+
+// drawLegalPlacements marks every spot where the piece that the player is about
+// to build can go. In the opening, the settlement spots show the pips of the
+// tiles around them to help pick a good one.
+func (ui *gameUI) drawLegalPlacements() {
+	pulse := placementPulse(time.Now())
+	w, h := ui.game.Size()
+	switch ui.game.State {
+	case game.BuildingFirstSettlement, game.BuildingSecondSettlement,
+		game.BuildingNewSettlement, game.BuildingNewCity:
+		opening := ui.game.State == game.BuildingFirstSettlement ||
+			ui.game.State == game.BuildingSecondSettlement
+		// corners reach one further than the tiles in both directions
+		for x := 0; x < w+2; x++ {
+			for y := 0; y < h+1; y++ {
+				corner := game.TileCorner{X: x, Y: y}
+				legal := ui.game.CanBuildSettlementAt(corner)
+				if ui.game.State == game.BuildingNewCity {
+					legal = ui.game.CanBuildCityAt(corner)
+				}
+				if !legal {
+					continue
+				}
+				screenX, screenY := cornerToScreen(corner)
+				if opening {
+					ui.graphics.drawPipHint(screenX, screenY, ui.game.CornerPips(corner), pulse)
+				} else {
+					ui.graphics.drawPlacementDot(screenX, screenY, pulse)
+				}
+			}
+		}
+	case game.BuildingFirstRoad, game.BuildingSecondRoad, game.BuildingNewRoad:
+		// edges have twice the resolution of tiles in x
+		for x := 0; x < 2*w+3; x++ {
+			for y := 0; y < h+1; y++ {
+				edge := game.TileEdge{X: x, Y: y}
+				if isValidEdge(x, y) && ui.game.CanBuildRoadAt(edge) {
+					screenX, screenY := edgeToScreen(edge)
+					ui.graphics.drawPlacementDot(screenX, screenY, pulse)
+				}
+			}
+		}
 	}
+}
+
+// placementPulse goes from 0 to 1 and back once per second.
+func placementPulse(t time.Time) float64 {
+	second := float64(t.UnixNano()%int64(time.Second)) / float64(time.Second)
+	return 0.5 - 0.5*math.Cos(2*math.Pi*second)
+}
 
+// drawPieceToBuild shows the piece that the player is about to build under the
+// mouse cursor. Where it can be built, it snaps to its place on the board.
+func (ui *gameUI) drawPieceToBuild() {
+	player := ui.game.GetCurrentPlayer()
 	gameX, gameY := ui.camera.windowToGame(ui.mouseX, ui.mouseY)
 
-	if ui.game.State == game.NotStarted {
-		ui.gui.draw(ui.graphics)
-	} else if ui.game.State == game.ChoosingNextAction {
-	} else if ui.game.State == game.BuildingFirstSettlement ||
+	if ui.game.State == game.BuildingFirstSettlement ||
 		ui.game.State == game.BuildingSecondSettlement ||
 		ui.game.State == game.BuildingNewSettlement {
 		corner, hit := screenToCorner(gameX, gameY)
@@ -373,14 +1039,20 @@ func (ui *gameUI) Draw() {
 		} else {
 			ui.graphics.drawHoveringCityAt(gameX, gameY, player.Color)
 		}
-	} else if ui.game.State == game.RollingDice {
-		const d = 100
-		ui.graphics.rect(gameW/2-2*d, gameH/2-d, 4*d, 2*d, [4]float32{1, 1, 1, 0.8})
-		ui.graphics.drawImageCenteredAt("dice", gameW/2, gameH/2)
 	}
 }
 
 func (ui *gameUI) stateInstruction() string {
+	if joined, ok := ui.remote.(joinedGame); ok &&
+		joined.client.Status() == network.Reconnecting {
+		return lang.Get(lang.Reconnecting)
+	}
+	if ui.game.State != game.NotStarted && !ui.isLocalTurn() {
+		if ui.waitingForReconnect() {
+			return lang.Get(lang.WaitingForReconnect)
+		}
+		return lang.Get(lang.WaitForOtherPlayers)
+	}
 	switch ui.game.State {
 	case game.NotStarted:
 		return lang.Get(lang.Menu)
@@ -407,32 +1079,69 @@ func (ui *gameUI) stateInstruction() string {
 }
 
 func (ui *gameUI) drawBaseGame() {
-	gl.Clear(gl.COLOR_BUFFER_BIT)
-	ui.graphics.drawBackground()
+	shown := ui.animations.shownGame(ui.game)
+	ui.graphics.drawBoardPieces(shown)
+	if !ui.animations.playing(robberAnimation) {
+		ui.graphics.drawRobber(tileToScreen(shown.Robber.Position))
+	}
+	ui.animations.draw(ui.graphics, true)
+}
 
-	// draw roads first
-	for _, p := range ui.game.GetPlayers() {
-		for _, r := range p.GetBuiltRoads() {
-			x, y := edgeToScreen(r.Position)
-			ui.graphics.drawRoadAt(x, y, r.Position, p.Color)
-		}
+// animateChanges starts the animations for what changed in the game since the
+// last frame, no matter if a player at this computer or over the network made
+// the change.
+func (ui *gameUI) animateChanges() {
+	if ui.lastSeen != nil && ui.lastSeen.State != game.NotStarted {
+		anims := changeAnimations(ui.lastSeen, ui.game, ui.shownPlayer().Color, ui)
+		ui.animations.play(ui.lastSeen, anims...)
 	}
-	// draw buildings above the roads
-	for _, p := range ui.game.GetPlayers() {
-		for _, s := range p.GetBuiltSettlements() {
-			x, y := cornerToScreen(s.Position)
-			ui.graphics.drawSettlementAt(x, y, p.Color)
-		}
-		for _, c := range p.GetBuiltCities() {
-			x, y := cornerToScreen(c.Position)
-			ui.graphics.drawCityAt(x, y, p.Color)
+	ui.lastSeen = ui.game.Clone()
+}
+
+func (ui *gameUI) boardToHUD(x, y int) (int, int) {
+	return ui.camera.windowToHUD(ui.camera.gameToWindow(x, y))
+}
+
+func (ui *gameUI) dieCenter(i int) (x, y int) {
+	return ui.hud.dice.dieCenter(i)
+}
+
+func (ui *gameUI) resourceSymbolCenter(r game.Resource) (x, y int) {
+	return ui.hud.resources.symbolCenter(r)
+}
+
+// shownResources returns the player whose cards the resource bar shows. While
+// animations play, the resources are shown as they were before.
+func (ui *gameUI) shownResources() game.Player {
+	shown := ui.shownPlayer()
+	for _, p := range ui.animations.shownGame(ui.game).GetPlayers() {
+		if p.Color == shown.Color {
+			shown = p
 		}
 	}
+	return shown
+}
 
-	ui.graphics.drawRobber(tileToScreen(ui.game.Robber.Position))
+// exportBoard writes the current board as PNG and SVG images into the working
+// directory and tells the player where they are.
+func (ui *gameUI) exportBoard() {
+	name := "board_" + time.Now().Format("2006-01-02_15-04-05")
+	if abs, err := filepath.Abs(name); err == nil {
+		name = abs
+	}
+	for _, ext := range []string{".png", ".svg"} {
+		if err := exportBoard(name+ext, ui.game, ui.graphics.images); err != nil {
+			ui.showDialog(newMessageBox(lang.ExportBoard, err.Error(), ui.graphics, nil))
+			return
+		}
+	}
+	text := fmt.Sprintf(lang.Get(lang.BoardExported), name, name)
+	ui.showDialog(newMessageBox(lang.ExportBoard, text, ui.graphics, nil))
 }
 
 func (ui *gameUI) Finish() {
+	ui.stopBrowsing()
+	ui.closeNetworkGame()
 	if err := settings.Settings.Save(); err != nil {
 		fmt.Println("cannot save settings:", err)
 	}
@@ -453,6 +1162,7 @@ func (ui *gameUI) DEBUG_initGame() {
 	ui.game.Players[2].Settlements[1].Position = game.TileCorner{6, 5}
 	ui.game.Players[2].Roads[0].Position = game.TileEdge{9, 5}
 	ui.game.Players[2].Roads[1].Position = game.TileEdge{11, 5}
+	ui.game.Reindex()
 
 	ui.game.State = game.RollingDice
 
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/gonutz/fontstash.go/fontstash"
+	"image"
+)
+
+// glRenderer draws with OpenGL into the current GL context. All images of the
+// atlas share one texture.
+type glRenderer struct {
+	atlas     uint32
+	images    map[string]*glImage
+	fontStash *fontstash.Stash
+	font      *glFont
+}
+
+// newGLRenderer uploads the atlas to the GPU. parts are the bounds of the
+// single images in the atlas by their ID.
+func newGLRenderer(atlas image.Image, parts map[string]image.Rectangle) (*glRenderer, error) {
+	r := &glRenderer{images: make(map[string]*glImage)}
+
+	all, err := NewGLImageFromImage(atlas)
+	if err != nil {
+		return nil, err
+	}
+	r.atlas = all.id
+	for id, part := range parts {
+		r.images[id], err = all.SubImage(part.Min.X, part.Min.Y, part.Dx(), part.Dy())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	r.fontStash = fontstash.New(512, 512)
+	fontID, err := r.fontStash.AddFont(resourcePath(fontFile))
+	if err != nil {
+		return nil, err
+	}
+	r.fontStash.SetYInverted(true)
+	r.font = NewGLFont(r.fontStash, fontID, fontSize, [4]float32{0, 0, 0, 1})
+
+	return r, nil
+}
+
+// clear sets the clear color every time, the theme may have changed it.
+func (r *glRenderer) clear() {
+	gl.ClearColor(clearColor[0], clearColor[1], clearColor[2], clearColor[3])
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+}
+
+func (r *glRenderer) rect(x, y, w, h int, color [4]float32) {
+	glRect(float32(x), float32(y), float32(w), float32(h),
+		color[0], color[1], color[2], color[3])
+}
+
+func glRect(x, y, w, h, r, g, b, a float32) {
+	gl.Disable(gl.TEXTURE_2D) // TODO do this once and remember the state
+	gl.Begin(gl.QUADS)
+	gl.Color4f(r, g, b, a)
+	gl.Vertex2f(x, y)
+	gl.Color4f(r, g, b, a)
+	gl.Vertex2f(x+w, y)
+	gl.Color4f(r, g, b, a)
+	gl.Vertex2f(x+w, y+h)
+	gl.Color4f(r, g, b, a)
+	gl.Vertex2f(x, y+h)
+	gl.End()
+}
+
+func (r *glRenderer) image(id string, x, y int) {
+	r.getImage(id).DrawAtXY(x, y)
+}
+
+func (r *glRenderer) coloredImage(id string, x, y int, color [4]float32) {
+	r.getImage(id).DrawColoredAtXY(x, y, color)
+}
+
+func (r *glRenderer) getImage(id string) *glImage {
+	if img, ok := r.images[id]; ok {
+		return img
+	}
+	panic("illegal image ID: '" + id + "'")
+}
+
+func (r *glRenderer) text(text string, x, y float64, color [4]float32) {
+	r.font.Color = color
+	r.font.Write(text, x, y)
+	r.fontStash.FlushDraw()
+}
+
+func (r *glRenderer) TextSize(text string) (w, h int) {
+	return r.font.TextSize(text)
+}
+
+func (r *glRenderer) fontSize() float64 {
+	return r.font.Size
+}
+
+func (r *glRenderer) setImage(id string, img image.Image) error {
+	glImg, err := NewGLImageFromImage(img)
+	if err != nil {
+		return err
+	}
+	if old, ok := r.images[id]; ok && old.id != r.atlas {
+		gl.DeleteTextures(1, &old.id)
+	}
+	r.images[id] = glImg
+	return nil
+}
@@ -2,102 +2,97 @@ package main
 
 import (
 	"bufio"
-	"github.com/go-gl/gl/v2.1/gl"
-	"github.com/gonutz/fontstash.go/fontstash"
 	"github.com/gonutz/settlers/game"
 	"image"
 	"image/draw"
 	_ "image/png"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 )
 
+// newGraphics creates graphics that draw with OpenGL, it needs a current GL
+// context.
 func newGraphics() (*graphics, error) {
-	g := &graphics{
-		images:   make(map[string]image.Image),
-		glImages: make(map[string]*glImage),
+	atlas, parts, err := loadImages()
+	if err != nil {
+		return nil, err
 	}
-	if err := g.init(); err != nil {
+	r, err := newGLRenderer(atlas, parts)
+	if err != nil {
 		return nil, err
 	}
-	return g, nil
+	return &graphics{images: subImages(atlas, parts), renderer: r}, nil
 }
 
-type graphics struct {
-	images     map[string]image.Image
-	glImages   map[string]*glImage
-	background *glImage
-	fontStash  *fontstash.Stash
-	font       *glFont
-}
-
-func (g *graphics) init() error {
-	if err := g.loadImages(); err != nil {
-		return err
-	}
-
-	gl.ClearColor(0, 0, 0.7, 1)
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-
-	g.fontStash = fontstash.New(512, 512)
-	fontID, err := g.fontStash.AddFont(resourcePath("MorrisRoman-Black.ttf"))
+// newSoftwareGraphics creates graphics that draw into the given image. Its
+// bounds are game coordinates, see screenBounds.
+func newSoftwareGraphics(dest *image.RGBA) (*graphics, error) {
+	atlas, parts, err := loadImages()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	g.fontStash.SetYInverted(true)
-	g.font = NewGLFont(g.fontStash, fontID, 45, [4]float32{0, 0, 0, 1})
+	images := subImages(atlas, parts)
+	return &graphics{
+		images:   images,
+		renderer: newSoftwareRenderer(dest, images),
+	}, nil
+}
 
-	return nil
+type graphics struct {
+	// images are the parts of the image atlas, by their IDs
+	images   map[string]image.Image
+	renderer renderer
 }
 
-func (g *graphics) loadImages() error {
+// loadImages loads the image atlas and the bounds of the single images in it.
+func loadImages() (atlas image.Image, parts map[string]image.Rectangle, err error) {
 	imgFile, err := os.Open(resourcePath("images", "all.png"))
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer imgFile.Close()
 
-	img, _, err := image.Decode(imgFile)
-	if err != nil {
-		return err
-	}
-
-	glImage, err := NewGLImageFromImage(img)
+	atlas, _, err = image.Decode(imgFile)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	tableFile, err := os.Open(resourcePath("images", "table.txt"))
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer tableFile.Close()
+	parts = make(map[string]image.Rectangle)
 	scanner := bufio.NewScanner(tableFile)
 	for scanner.Scan() {
-		parts := strings.Split(scanner.Text(), " ")
-		if len(parts) != 5 {
+		fields := strings.Split(scanner.Text(), " ")
+		if len(fields) != 5 {
 			continue
 		}
-		id := parts[0]
-		x, _ := strconv.Atoi(parts[1])
-		y, _ := strconv.Atoi(parts[2])
-		w, _ := strconv.Atoi(parts[3])
-		h, _ := strconv.Atoi(parts[4])
+		id := fields[0]
+		x, _ := strconv.Atoi(fields[1])
+		y, _ := strconv.Atoi(fields[2])
+		w, _ := strconv.Atoi(fields[3])
+		h, _ := strconv.Atoi(fields[4])
 		x++
 		y++
 		w -= 2
 		h -= 2
-		g.images[id] = subImage{img, image.Rect(x, y, x+w, y+h)}
-		g.glImages[id], err = glImage.SubImage(x, y, w, h)
-		if err != nil {
-			return err
-		}
+		parts[id] = image.Rect(x, y, x+w, y+h)
 	}
 
-	return nil
+	return atlas, parts, nil
+}
+
+func subImages(atlas image.Image, parts map[string]image.Rectangle) map[string]image.Image {
+	images := make(map[string]image.Image)
+	for id, bounds := range parts {
+		images[id] = subImage{atlas, bounds}
+	}
+	return images
 }
 
 func resourcePath(foldersAndFile ...string) string {
@@ -112,6 +107,12 @@ func resourcePath(foldersAndFile ...string) string {
 }
 
 func (gr *graphics) createGameBackground(g *game.Game) error {
+	return gr.renderer.setImage("background", gr.gameBackground(g))
+}
+
+// gameBackground draws the parts of the game that do not change while playing,
+// the tiles with their numbers and the harbors.
+func (gr *graphics) gameBackground(g *game.Game) *image.RGBA {
 	dest := image.NewRGBA(image.Rect(0, 0, gameW, gameH))
 
 	images := gr.images
@@ -200,127 +201,107 @@ func (gr *graphics) createGameBackground(g *game.Game) error {
 		}
 	}
 
-	var err error
-	gr.background, err = NewGLImageFromImage(dest)
-
-	return err
+	return dest
 }
 
 func (g *graphics) drawBackground() {
-	g.background.DrawAtXY(0, 0)
+	g.renderer.image("background", 0, 0)
 }
 
-func (g *graphics) showInstruction(msg string, color game.Color) {
-	var x, y, w, h float32
-	textWidth, textHeight := g.font.TextSize(msg)
-	const border = 25
-	w, h = float32(textWidth)+2*border, 90
-	x, y = (gameW-w)/2, -topBorder
-	glRect(x, y, w, h, 0.5, 0.5, 1, 0.8)
-	g.fontStash.BeginDraw()
-	g.font.Color = gameColorToFloats(color)
-	g.font.Write(msg, float64(x+border), float64(y+float32(100-textHeight)/2)+g.font.Size)
-	g.fontStash.EndDraw()
+// drawBoard draws the background with all pieces on it and the robber.
+func (gr *graphics) drawBoard(g *game.Game) {
+	gr.drawBoardPieces(g)
+	gr.drawRobber(tileToScreen(g.Robber.Position))
 }
 
-func gameColorToFloats(c game.Color) [4]float32 {
-	switch c {
-	case game.Red:
-		return [4]float32{0.7, 0, 0, 1}
-	case game.Blue:
-		return [4]float32{0, 0, 0.6, 1}
-	case game.White:
-		return [4]float32{1, 1, 1, 1}
-	default: // orange
-		return [4]float32{1, 0.5, 0, 1}
+// drawBoardPieces draws the background and all roads and buildings, but not the
+// robber.
+func (gr *graphics) drawBoardPieces(g *game.Game) {
+	gr.renderer.clear()
+	gr.drawBackground()
+
+	// draw roads first
+	for _, p := range g.GetPlayers() {
+		for _, r := range p.GetBuiltRoads() {
+			x, y := edgeToScreen(r.Position)
+			gr.drawRoadAt(x, y, r.Position, p.Color)
+		}
+	}
+	// draw buildings above the roads
+	for _, p := range g.GetPlayers() {
+		for _, s := range p.GetBuiltSettlements() {
+			x, y := cornerToScreen(s.Position)
+			gr.drawSettlementAt(x, y, p.Color)
+		}
+		for _, c := range p.GetBuiltCities() {
+			x, y := cornerToScreen(c.Position)
+			gr.drawCityAt(x, y, p.Color)
+		}
 	}
 }
 
-func glRect(x, y, w, h, r, g, b, a float32) {
-	gl.Disable(gl.TEXTURE_2D) // TODO do this once and remember the state
-	gl.Begin(gl.QUADS)
-	gl.Color4f(r, g, b, a)
-	gl.Vertex2f(x, y)
-	gl.Color4f(r, g, b, a)
-	gl.Vertex2f(x+w, y)
-	gl.Color4f(r, g, b, a)
-	gl.Vertex2f(x+w, y+h)
-	gl.Color4f(r, g, b, a)
-	gl.Vertex2f(x, y+h)
-	gl.End()
+func gameColorToFloats(c game.Color) [4]float32 {
+	return currentPalette.text[c]
 }
 
 func (g *graphics) rect(x, y, w, h int, color [4]float32) {
-	glRect(float32(x), float32(y), float32(w), float32(h),
-		color[0], color[1], color[2], color[3])
+	g.renderer.rect(x, y, w, h, color)
+}
+
+// frame draws the outline of r, thickness pixels wide, outside of r.
+func (g *graphics) frame(r rect, thickness int, color [4]float32) {
+	t := thickness
+	g.rect(r.x-t, r.y-t, r.w+2*t, t, color)
+	g.rect(r.x-t, r.y+r.h, r.w+2*t, t, color)
+	g.rect(r.x-t, r.y, t, r.h, color)
+	g.rect(r.x+r.w, r.y, t, r.h, color)
 }
 
 func (g *graphics) drawSettlementAt(x, y int, color game.Color) {
-	img := g.getGLImage("settlement_" + colorToString(color))
-	img.DrawAtXY(x-img.Width/2, y-img.Height/2)
+	g.drawColoredImageCenteredAt("settlement_"+colorToString(color), x, y, pieceTint(color))
+	g.drawPlayerMarker(x, y+5, color, 3)
 }
 
 func (g *graphics) drawHoveringSettlementAt(x, y int, color game.Color) {
-	img := g.getGLImage("settlement_" + colorToString(color))
-	col := playerColor(color)
-	col[3] = 0.6
-	img.DrawColoredAtXY(x-img.Width/2, y-img.Height/2, col)
+	g.drawColoredImageCenteredAt("settlement_"+colorToString(color), x, y, hoveringTint(color))
 }
 
+// colorToString names the piece images of the player in the current palette.
 func colorToString(color game.Color) string {
-	switch color {
-	case game.White:
-		return "white"
-	case game.Blue:
-		return "blue"
-	case game.Red:
-		return "red"
-	default:
-		return "orange"
-	}
+	return currentPalette.pieces[color]
 }
 
 func (g *graphics) drawCityAt(x, y int, color game.Color) {
-	img := g.getGLImage("city_" + colorToString(color))
-	img.DrawAtXY(x-img.Width/2, y-img.Height/2)
+	g.drawColoredImageCenteredAt("city_"+colorToString(color), x, y, pieceTint(color))
+	g.drawPlayerMarker(x, y+8, color, 4)
 }
 
 func (g *graphics) drawHoveringCityAt(x, y int, color game.Color) {
-	img := g.getGLImage("city_" + colorToString(color))
-	col := playerColor(color)
-	col[3] = 0.6
-	img.DrawColoredAtXY(x-img.Width/2, y-img.Height/2, col)
+	g.drawColoredImageCenteredAt("city_"+colorToString(color), x, y, hoveringTint(color))
 }
 
-func playerColor(color game.Color) [4]float32 {
-	switch color {
-	case game.White:
-		return [4]float32{1, 1, 1, 1}
-	case game.Blue:
-		return [4]float32{0.5, 0.5, 1, 1}
-	case game.Red:
-		return [4]float32{1, 0.5, 0.5, 1}
-	default:
-		return [4]float32{1, 0.5, 0, 1}
+// hoveringTint makes the piece that is about to be built see-through.
+func hoveringTint(color game.Color) [4]float32 {
+	tint := pieceTint(color)
+	light := playerColor(color)
+	for i := 0; i < 3; i++ {
+		tint[i] *= light[i]
 	}
+	tint[3] = 0.6
+	return tint
+}
+
+func playerColor(color game.Color) [4]float32 {
+	return currentPalette.light[color]
 }
 
 func fullPlayerColor(color game.Color) [4]float32 {
-	switch color {
-	case game.White:
-		return [4]float32{1, 1, 1, 1}
-	case game.Blue:
-		return [4]float32{0, 0, 1, 1}
-	case game.Red:
-		return [4]float32{1, 0, 0, 1}
-	default:
-		return [4]float32{1, 0.5, 0, 1}
-	}
+	return currentPalette.full[color]
 }
 
 func (g *graphics) drawRoadAt(x, y int, edge game.TileEdge, c game.Color) {
-	img := g.getGLImage("road_" + colorToString(c) + "_" + roadDirection(edge))
-	img.DrawAtXY(x-img.Width/2, y-img.Height/2)
+	g.drawColoredImageCenteredAt("road_"+colorToString(c)+"_"+roadDirection(edge), x, y, pieceTint(c))
+	g.drawPlayerMarker(x, y, c, 3)
 }
 
 func roadDirection(edge game.TileEdge) string {
@@ -334,51 +315,39 @@ func roadDirection(edge game.TileEdge) string {
 }
 
 func (g *graphics) drawRobber(x, y, w, h int) {
-	img := g.getGLImage("robber")
-	img.DrawAtXY(x+(w-img.Width)/2, y+(h-img.Height)/2)
+	imgW, imgH := g.imageSize("robber")
+	g.renderer.image("robber", x+(w-imgW)/2, y+(h-imgH)/2)
 }
 
 func (g *graphics) drawHoveringRoadAt(x, y int, color game.Color) {
-	img := g.getGLImage("road_" + colorToString(color) + "_up")
-	col := playerColor(color)
-	col[3] = 0.6
-	img.DrawColoredAtXY(x-img.Width/2, y-img.Height/2, col)
-}
-
-func (g *graphics) drawResources(resources [game.ResourceCount]int, color [4]float32) {
-	maxWidth, maxHeight := 0, 0
-	var images [game.ResourceCount]*glImage
-	for i := 0; i < game.ResourceCount; i++ {
-		resource := game.Resource(i)
-		images[i] = g.getGLImage(resourceToString(resource) + "_symbol")
-		if images[i].Width > maxWidth {
-			maxWidth = images[i].Width
-		}
-		if images[i].Height > maxHeight {
-			maxHeight = images[i].Height
-		}
-	}
+	g.drawColoredImageCenteredAt("road_"+colorToString(color)+"_up", x, y, hoveringTint(color))
+}
+
+// drawPlacementDot marks a spot where a piece can be built. pulse goes from 0
+// to 1 and back so the dots throb.
+func (g *graphics) drawPlacementDot(x, y int, pulse float64) {
+	radius := 8 + int(4*pulse+0.5)
+	g.disc(x, y, radius+2, [4]float32{0, 0, 0, 0.6})
+	g.disc(x, y, radius, [4]float32{1, 1, 1, 0.6 + 0.4*float32(pulse)})
+}
 
-	const hMargin = 20
-	overallWidth := game.ResourceCount*maxWidth + (game.ResourceCount-1)*hMargin
-	x, y := (gameW-overallWidth)/2, gameH+30
-	textY := float64(y+maxHeight) + g.font.Size
-	g.font.Color = color
-	const border = 15
-	glRect(
-		float32(x)-border,
-		float32(y)-border,
-		float32(overallWidth)+2*border,
-		float32(textY)-float32(y)+2*border,
-		0.8, 0.6, 0.5, 0.8,
-	)
-	for i := 0; i < game.ResourceCount; i++ {
-		images[i].DrawAtXY(x+(maxWidth-images[i].Width)/2, y)
-		text := strconv.Itoa(resources[i])
-		textW, _ := g.font.TextSize(text)
-		fontX := float64(x + (maxWidth-textW)/2)
-		g.font.Write(text, fontX, textY)
-		x += maxWidth + hMargin
+// drawPipHint marks a spot for a settlement in the opening with the pips of the
+// tiles around it.
+func (g *graphics) drawPipHint(x, y, pips int, pulse float64) {
+	const radius = 22
+	g.disc(x, y, radius+2, [4]float32{0, 0, 0, 0.6})
+	g.disc(x, y, radius, [4]float32{1, 1, 1, 0.7 + 0.3*float32(pulse)})
+	g.writeTextLineCenteredInRect(strconv.Itoa(pips),
+		rect{x - radius, y - radius, 2 * radius, 2 * radius}, [4]float32{0, 0, 0, 1})
+}
+
+// disc draws a filled circle out of one rect per row.
+func (g *graphics) disc(x, y, radius int, color [4]float32) {
+	r := float64(radius)
+	for dy := -radius; dy < radius; dy++ {
+		fy := float64(dy) + 0.5
+		half := int(math.Sqrt(r*r-fy*fy) + 0.5)
+		g.rect(x-half, y+dy, 2*half, 1, color)
 	}
 }
 
@@ -400,45 +369,40 @@ func resourceToString(r game.Resource) string {
 }
 
 func (g *graphics) drawImageCenteredAt(id string, x, y int) {
-	img := g.getGLImage(id)
-	img.DrawAtXY(x-img.Width/2, y-img.Height/2)
-}
-
-func (g *graphics) getGLImage(id string) *glImage {
-	if img, ok := g.glImages[id]; ok {
-		return img
-	}
-	panic("illegal image ID: '" + id + "'")
+	w, h := g.imageSize(id)
+	g.renderer.image(id, x-w/2, y-h/2)
 }
 
 func (g *graphics) drawColoredImageCenteredAt(id string, x, y int, color [4]float32) {
-	img := g.getGLImage(id)
-	img.DrawColoredAtXY(x-img.Width/2, y-img.Height/2, color)
+	w, h := g.imageSize(id)
+	g.renderer.coloredImage(id, x-w/2, y-h/2, color)
 }
 
 func (g *graphics) imageSize(id string) (w, h int) {
-	img := g.getGLImage(id)
-	return img.Width, img.Height
+	if img, ok := g.images[id]; ok {
+		return img.Bounds().Dx(), img.Bounds().Dy()
+	}
+	panic("illegal image ID: '" + id + "'")
+}
+
+func dieImage(face int) string {
+	return "die_" + strconv.Itoa(face)
 }
 
-func (g *graphics) drawDice(dice [2]int) {
-	x, y := 60, 100
-	ids := []string{"", "die_1", "die_2", "die_3", "die_4", "die_5", "die_6"}
-	g.drawImageCenteredAt(ids[dice[0]], x, y)
-	g.drawImageCenteredAt(ids[dice[1]], x+100, y)
+// TextSize returns the size of a line of text.
+func (g *graphics) TextSize(text string) (w, h int) {
+	return g.renderer.TextSize(text)
 }
 
 func (g *graphics) writeTextLineCenteredInRect(text string, r rect, color [4]float32) {
-	g.font.Color = color
-	w, h := g.font.TextSize(text)
+	w, h := g.TextSize(text)
 	x := float64(r.x) + float64(r.w-w)/2
 	y := float64(r.y) + float64(r.h+h)/2
-	g.font.Write(text, x, y)
-	g.fontStash.FlushDraw()
+	g.renderer.text(text, x, y, color)
 }
 
 func (g *graphics) writeLeftAlignedVerticallyCenteredAt(text string, x, centerY int, color [4]float32) {
-	w, h := g.font.TextSize(text)
+	w, h := g.TextSize(text)
 	y := centerY - h/2
 	g.writeTextLineCenteredInRect(text, rect{x, y, w, h}, color)
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"github.com/go-gl/glfw/v3.1/glfw"
 	"github.com/gonutz/settlers/lang"
+	"strconv"
+	"strings"
 )
 
 var (
@@ -13,6 +15,18 @@ var (
 	menuColdFontColor      = [4]float32{0.7, 0.7, 0.7, 1}
 	checkBoxCheckedColor   = [4]float32{0.5, 1, 0.5, 1}
 	checkBoxUncheckedColor = [4]float32{1, 0.5, 0.5, 1}
+	focusFrameColor        = [4]float32{1, 0.85, 0, 1}
+	textLogBackColor       = [4]float32{0.8, 0.6, 0.5, 0.8}
+	textLogFontColor       = [4]float32{0, 0, 0, 1}
+)
+
+var (
+	// focusFrameWidth is how thick the frame around the focused element is.
+	focusFrameWidth = 4
+	// buttonW and buttonH are the size of the buttons, check boxes and text
+	// boxes in the menus that do not have their own size.
+	buttonW = 500
+	buttonH = 80
 )
 
 type guiElement interface {
@@ -23,7 +37,62 @@ type guiElement interface {
 	// click returns the ID of the activated action or -1 if none was activated.
 	click(x, y int) (actionID int)
 	runeTyped(rune)
-	keyPressed(glfw.Key)
+	// keyPressed returns the ID of the activated action or -1, like click.
+	keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int)
+}
+
+// focusable elements can get the keyboard focus. The focus manager sends keys
+// and typed runes only to the focused element.
+type focusable interface {
+	guiElement
+	canFocus() bool
+	setFocus(bool)
+	// focusRect is the area that gets a frame while the element has the focus.
+	focusRect() rect
+}
+
+// parent elements contain other elements that can get the focus.
+type parent interface {
+	children() []guiElement
+}
+
+// scrollable elements scroll when the mouse wheel turns over them.
+type scrollable interface {
+	guiElement
+	// scrollBy scrolls up for positive n and down for negative n.
+	scrollBy(n int)
+}
+
+// scrollableAt returns the innermost element under x,y that can scroll or nil
+// if there is none.
+func scrollableAt(p parent, x, y int) scrollable {
+	var found scrollable
+	for _, child := range p.children() {
+		if s, ok := child.(scrollable); ok && s.bounds().contains(x, y) {
+			found = s
+		}
+		if c, ok := child.(parent); ok {
+			if inner := scrollableAt(c, x, y); inner != nil {
+				found = inner
+			}
+		}
+	}
+	return found
+}
+
+// focusOrder returns all elements in the tree under p that can get the focus
+// right now, in the order that Tab moves through them.
+func focusOrder(p parent) []focusable {
+	var order []focusable
+	for _, child := range p.children() {
+		if f, ok := child.(focusable); ok && f.canFocus() {
+			order = append(order, f)
+		}
+		if c, ok := child.(parent); ok {
+			order = append(order, focusOrder(c)...)
+		}
+	}
+	return order
 }
 
 // boundingBox returns the smallest rect that encompasses all bounds.
@@ -33,7 +102,7 @@ func boundingBox(bucket bounderBucket) rect {
 	}
 
 	bounds := bucket.At(0).bounds()
-	l, t, r, b := bounds.x, bounds.y, bounds.x, bounds.y
+	l, t, r, b := bounds.x, bounds.y, bounds.x+bounds.w, bounds.y+bounds.h
 	for i := 1; i < bucket.Len(); i++ {
 		bounds = bucket.At(i).bounds()
 		if bounds.x < l {
@@ -122,11 +191,18 @@ func (w *window) runeTyped(r rune) {
 	w.composite.runeTyped(r)
 }
 
-func (w *window) keyPressed(key glfw.Key) {
+func (w *window) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
 	if !w.visible {
-		return
+		return -1
+	}
+	return w.composite.keyPressed(key, mods)
+}
+
+func (w *window) children() []guiElement {
+	if !w.visible {
+		return nil
 	}
-	w.composite.keyPressed(key)
+	return w.composite.children()
 }
 
 // composite
@@ -170,9 +246,140 @@ func (c *composite) runeTyped(r rune) {
 	}
 }
 
-func (c *composite) keyPressed(key glfw.Key) {
+func (c *composite) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	actionID = -1
 	for _, e := range c.elems {
-		e.keyPressed(key)
+		if id := e.keyPressed(key, mods); id != -1 && actionID == -1 {
+			actionID = id
+		}
+	}
+	return
+}
+
+func (c *composite) children() []guiElement { return c.elems }
+
+// focus manager
+
+// newFocusManager creates the root of a GUI tree. Tab and Shift+Tab move the
+// focus through the elements in the tree, all other keys and the typed runes
+// go to the focused element. Clicking an element gives it the focus as well.
+func newFocusManager(elems ...guiElement) *focusManager {
+	return &focusManager{composite: newComposite(elems...)}
+}
+
+type focusManager struct {
+	*composite
+	focused focusable
+	// showFrame is set when the focus was moved with the keyboard, a mouse
+	// click moves the focus without framing it
+	showFrame bool
+}
+
+func (m *focusManager) draw(g *graphics) {
+	m.dropLostFocus()
+	m.composite.draw(g)
+	if m.focused != nil && m.showFrame {
+		g.frame(m.focused.focusRect(), focusFrameWidth, focusFrameColor)
+	}
+}
+
+func (m *focusManager) click(x, y int) (actionID int) {
+	// the clicked element gets the focus before the click so it can handle the
+	// click knowing that it has the focus, e.g. a text box moves its caret
+	var clicked focusable
+	for _, f := range focusOrder(m.composite) {
+		// children come after their parents, so the last hit is the innermost
+		if f.bounds().contains(x, y) {
+			clicked = f
+		}
+	}
+	m.focus(clicked)
+	m.showFrame = false
+	actionID = m.composite.click(x, y)
+	// the click might have hidden the clicked element
+	m.dropLostFocus()
+	return actionID
+}
+
+func (m *focusManager) runeTyped(r rune) {
+	m.dropLostFocus()
+	if m.focused != nil {
+		m.focused.runeTyped(r)
+	}
+}
+
+func (m *focusManager) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	m.dropLostFocus()
+	if key == glfw.KeyTab {
+		if mods&glfw.ModShift != 0 {
+			m.moveFocus(-1)
+		} else {
+			m.moveFocus(1)
+		}
+		return -1
+	}
+	if m.focused != nil {
+		m.showFrame = true
+		return m.focused.keyPressed(key, mods)
+	}
+	return -1
+}
+
+// scrollAt scrolls the element under x,y by n, see scrollable.
+func (m *focusManager) scrollAt(x, y, n int) {
+	if s := scrollableAt(m.composite, x, y); s != nil {
+		s.scrollBy(n)
+	}
+}
+
+// moveFocus moves the focus step elements forward in the focus order, or
+// backward for negative steps. It wraps around at both ends.
+func (m *focusManager) moveFocus(step int) {
+	m.showFrame = true
+	order := focusOrder(m.composite)
+	if len(order) == 0 {
+		m.focus(nil)
+		return
+	}
+	next := 0
+	if step < 0 {
+		next = len(order) - 1
+	}
+	for i, f := range order {
+		if f == m.focused {
+			next = ((i+step)%len(order) + len(order)) % len(order)
+		}
+	}
+	m.focus(order[next])
+}
+
+// dropLostFocus takes the focus away from the focused element if it cannot have
+// it anymore, e.g. because its window was hidden or it was disabled.
+func (m *focusManager) dropLostFocus() {
+	if m.focused != nil && !m.canFocus(m.focused) {
+		m.focus(nil)
+	}
+}
+
+func (m *focusManager) canFocus(f focusable) bool {
+	for _, candidate := range focusOrder(m.composite) {
+		if candidate == f {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *focusManager) focus(f focusable) {
+	if f == m.focused {
+		return
+	}
+	if m.focused != nil {
+		m.focused.setFocus(false)
+	}
+	m.focused = f
+	if f != nil {
+		f.setFocus(true)
 	}
 }
 
@@ -227,8 +434,18 @@ func (b *button) click(x, y int) int {
 	return -1
 }
 
-func (*button) runeTyped(rune)      {}
-func (*button) keyPressed(glfw.Key) {}
+func (*button) runeTyped(rune) {}
+
+func (b *button) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	if b.enabled && isActivationKey(key) {
+		return b.action
+	}
+	return -1
+}
+
+func (b *button) canFocus() bool  { return b.enabled }
+func (b *button) setFocus(bool)   {}
+func (b *button) focusRect() rect { return b.rect }
 
 func (b *button) setEnabled(enabled bool) {
 	b.enabled = enabled
@@ -239,42 +456,76 @@ func (b *button) setEnabled(enabled bool) {
 
 // text box
 
-func newTextBox(captionID lang.Item, bounds rect, font textSizer) *textBox {
+func newTextBox(captionID lang.Item, bounds rect, font textSizer, clip clipboard) *textBox {
 	return &textBox{
 		rect:      bounds,
 		captionID: captionID,
 		font:      font,
+		clipboard: clip,
 	}
 }
 
 type textBox struct {
 	rect
-	hot               bool
-	captionID         lang.Item
-	text              string
-	textLengthInRunes int
-	font              textSizer
-	captionRect       rect
-	textRect          rect
-	disabled          bool
-	textChangeAction  func(string)
+	focused   bool
+	captionID lang.Item
+	text      string
+	// caret is the rune index in text where typed runes go, anchor is the
+	// other end of the selection. Nothing is selected if they are the same.
+	caret, anchor    int
+	font             textSizer
+	clipboard        clipboard
+	captionRect      rect
+	textRect         rect
+	disabled         bool
+	textChangeAction func(string)
+	validate         func(string) bool
+}
+
+// clipboard is where text boxes copy text to and paste it from.
+type clipboard interface {
+	SetClipboardString(text string)
+	GetClipboardString() (string, error)
 }
 
 func (t *textBox) onTextChange(action func(string)) {
 	t.textChangeAction = action
 }
 
+// setValidator sets the function that decides which texts are allowed. Edits
+// that would make the text invalid are ignored. Since the text is checked
+// after every typed rune, the function has to accept the beginnings of valid
+// texts as well.
+func (t *textBox) setValidator(valid func(string) bool) {
+	t.validate = valid
+}
+
 func (t *textBox) bounds() rect          { return t.rect }
 func (t *textBox) setBounds(bounds rect) { t.rect = bounds }
 
+// click moves the caret to the clicked position. The focus manager gives the
+// text box the focus before that.
 func (t *textBox) click(x, y int) int {
-	if t.disabled {
-		return -1
+	t.recalcRects()
+	if t.focused && t.textRect.contains(x, y) {
+		t.caret = t.runeIndexAt(x)
+		t.anchor = t.caret
 	}
-	t.hot = t.contains(x, y)
 	return -1
 }
 
+func (t *textBox) canFocus() bool  { return !t.disabled }
+func (t *textBox) focusRect() rect { return t.rect }
+
+// setFocus selects all text when the text box gets the focus, so typing
+// replaces it.
+func (t *textBox) setFocus(f bool) {
+	if f && !t.focused {
+		t.anchor, t.caret = 0, len([]rune(t.text))
+	}
+	t.focused = f
+}
+
 func (t *textBox) draw(g *graphics) {
 	t.recalcRects()
 	fontColor := menuFontColor
@@ -284,10 +535,20 @@ func (t *textBox) draw(g *graphics) {
 
 	g.rect(t.x, t.y, t.w, t.h, menuColdBackColor)
 	g.writeTextLineCenteredInRect(lang.Get(t.captionID), t.captionRect, fontColor)
-	if t.hot {
+	_, textH := t.font.TextSize(t.text)
+	textY := t.textRect.y + (t.textRect.h-textH)/2
+	if t.focused {
 		g.rect(t.textRect.x, t.textRect.y, t.textRect.w, t.textRect.h, menuHotBackColor)
+		start, end := t.selection()
+		if start != end {
+			x := t.runeX(start)
+			g.rect(x, textY, t.runeX(end)-x, textH, menuColdBackColor)
+		}
 	}
 	g.writeTextLineCenteredInRect(t.text, t.textRect, fontColor)
+	if t.focused {
+		g.rect(t.runeX(t.caret)-1, textY, 2, textH, fontColor)
+	}
 }
 
 func (t *textBox) recalcRects() {
@@ -297,45 +558,162 @@ func (t *textBox) recalcRects() {
 	t.textRect = rect{t.x + 2*margin + captionW, t.y, t.w - 2*margin - captionW, t.h}
 }
 
+// runeX returns the x coordinate of the left side of the rune at index i in the
+// text, for i = length of the text it is the right side of the last rune.
+func (t *textBox) runeX(i int) int {
+	textW, _ := t.font.TextSize(t.text)
+	prefixW, _ := t.font.TextSize(string([]rune(t.text)[:i]))
+	return t.textRect.x + (t.textRect.w-textW)/2 + prefixW
+}
+
+// runeIndexAt returns the caret position closest to x.
+func (t *textBox) runeIndexAt(x int) int {
+	best, bestDist := 0, -1
+	for i := 0; i <= len([]rune(t.text)); i++ {
+		dist := t.runeX(i) - x
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist == -1 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// selection returns the selected rune range, start and end are the same if
+// nothing is selected.
+func (t *textBox) selection() (start, end int) {
+	// the text might have been set from outside since the last edit
+	n := len([]rune(t.text))
+	if t.caret > n {
+		t.caret = n
+	}
+	if t.anchor > n {
+		t.anchor = n
+	}
+	if t.caret < t.anchor {
+		return t.caret, t.anchor
+	}
+	return t.anchor, t.caret
+}
+
 func (t *textBox) mouseMovedTo(x, y int) {}
 
 func (t *textBox) runeTyped(r rune) {
-	if t.disabled {
+	if t.disabled || !t.focused {
 		return
 	}
-	if t.hot {
-		newText := t.text + string(r)
-		w, _ := t.font.TextSize(newText)
-		if w < t.textRect.w {
-			t.setText(newText)
-			t.textLengthInRunes++
+	start, end := t.selection()
+	t.replace(start, end, string(r))
+}
+
+func (t *textBox) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	if t.disabled || !t.focused {
+		return -1
+	}
+	start, end := t.selection()
+	n := len([]rune(t.text))
+	shift := mods&glfw.ModShift != 0
+	if mods&glfw.ModControl != 0 {
+		switch key {
+		case glfw.KeyA:
+			t.anchor, t.caret = 0, n
+		case glfw.KeyC:
+			t.copy(start, end)
+		case glfw.KeyX:
+			t.copy(start, end)
+			t.replace(start, end, "")
+		case glfw.KeyV:
+			t.paste(start, end)
 		}
+		return -1
 	}
+	switch key {
+	case glfw.KeyLeft:
+		if start != end && !shift {
+			t.moveCaret(start, false)
+		} else {
+			t.moveCaret(t.caret-1, shift)
+		}
+	case glfw.KeyRight:
+		if start != end && !shift {
+			t.moveCaret(end, false)
+		} else {
+			t.moveCaret(t.caret+1, shift)
+		}
+	case glfw.KeyHome:
+		t.moveCaret(0, shift)
+	case glfw.KeyEnd:
+		t.moveCaret(n, shift)
+	case glfw.KeyBackspace:
+		if start == end && start > 0 {
+			start--
+		}
+		t.replace(start, end, "")
+	case glfw.KeyDelete:
+		if start == end && end < n {
+			end++
+		}
+		t.replace(start, end, "")
+	}
+	return -1
 }
 
-func (t *textBox) keyPressed(key glfw.Key) {
-	if t.disabled {
+// moveCaret moves the caret to rune index i. If extend is true, the selection
+// is extended to there, otherwise it is cleared.
+func (t *textBox) moveCaret(i int, extend bool) {
+	if i < 0 {
+		i = 0
+	}
+	if n := len([]rune(t.text)); i > n {
+		i = n
+	}
+	t.caret = i
+	if !extend {
+		t.anchor = i
+	}
+}
+
+// replace replaces the runes from start to end with s and puts the caret after
+// the new text. It does nothing if the new text is invalid or too wide.
+func (t *textBox) replace(start, end int, s string) {
+	runes := []rune(t.text)
+	newText := string(runes[:start]) + s + string(runes[end:])
+	if t.validate != nil && !t.validate(newText) {
 		return
 	}
-	if t.hot {
-		if key == glfw.KeyBackspace && len(t.text) > 0 {
-			var last int
-			for i := range t.text {
-				last = i
-			}
-			t.setText(t.text[:last])
-			t.textLengthInRunes--
-		}
-		if key == glfw.KeyEnter || key == glfw.KeyKPEnter {
-			t.hot = false
-		}
+	t.recalcRects()
+	if w, _ := t.font.TextSize(newText); w >= t.textRect.w && newText != "" {
+		return
+	}
+	t.setText(newText)
+	t.caret = start + len([]rune(s))
+	t.anchor = t.caret
+}
+
+func (t *textBox) copy(start, end int) {
+	if t.clipboard != nil && start != end {
+		t.clipboard.SetClipboardString(string([]rune(t.text)[start:end]))
+	}
+}
+
+func (t *textBox) paste(start, end int) {
+	if t.clipboard == nil {
+		return
+	}
+	text, err := t.clipboard.GetClipboardString()
+	if err == nil {
+		// a copied line often ends in a line break, it must not end up in the
+		// one line of the text box
+		t.replace(start, end, strings.TrimSpace(text))
 	}
 }
 
 func (t *textBox) setEnabled(enabled bool) {
 	t.disabled = !enabled
 	if t.disabled {
-		t.hot = false
+		t.focused = false
 	}
 }
 
@@ -346,6 +724,58 @@ func (t *textBox) setText(text string) {
 	}
 }
 
+// validPortText allows the digits of a network port, that is a number up to
+// 65535.
+func validPortText(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	if text == "" {
+		return true
+	}
+	port, err := strconv.Atoi(text)
+	return err == nil && port <= 65535
+}
+
+// validHostText allows host names and IP addresses, or the beginnings of them.
+// If all parts between the dots are numbers, it is an IPv4 address and the
+// numbers must not be greater than 255.
+func validHostText(text string) bool {
+	if len(text) > 253 {
+		return false
+	}
+	for _, r := range text {
+		letter := 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+		digit := '0' <= r && r <= '9'
+		if !letter && !digit && r != '.' && r != '-' && r != ':' {
+			return false
+		}
+	}
+	labels := strings.Split(text, ".")
+	allNumbers := true
+	for _, label := range labels {
+		if len(label) > 63 || strings.HasPrefix(label, "-") {
+			return false
+		}
+		if _, err := strconv.Atoi(label); err != nil && label != "" {
+			allNumbers = false
+		}
+	}
+	if allNumbers && !strings.Contains(text, ":") {
+		if len(labels) > 4 {
+			return false
+		}
+		for _, label := range labels {
+			if n, _ := strconv.Atoi(label); len(label) > 3 || n > 255 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 type textSizer interface {
 	TextSize(text string) (w, h int)
 }
@@ -418,8 +848,22 @@ func (c *checkBox) setChecked(checked bool) {
 	}
 }
 
-func (c *checkBox) runeTyped(r rune)        {}
-func (c *checkBox) keyPressed(key glfw.Key) {}
+func (c *checkBox) runeTyped(r rune) {}
+
+// keyPressed toggles the check box for Enter and Space, like a click does.
+func (c *checkBox) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	if isActivationKey(key) {
+		c.setChecked(!c.checked)
+		if c.checked {
+			return c.id
+		}
+	}
+	return -1
+}
+
+func (c *checkBox) canFocus() bool  { return true }
+func (c *checkBox) setFocus(bool)   {}
+func (c *checkBox) focusRect() rect { return c.rect }
 
 func (c *checkBox) onCheckChange(action func(bool)) {
 	c.checkChangeEvent = action
@@ -508,6 +952,39 @@ func (group *checkBoxGroup) click(x, y int) (actionID int) {
 	return -1
 }
 
+// keyPressed checks the previous or next box for the arrow keys, it wraps
+// around at both ends.
+func (group *checkBoxGroup) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	n := len(group.boxes)
+	switch key {
+	case glfw.KeyUp, glfw.KeyLeft:
+		return group.check((group.checkedIndex + n - 1) % n)
+	case glfw.KeyDown, glfw.KeyRight:
+		return group.check((group.checkedIndex + 1) % n)
+	}
+	return -1
+}
+
+// check checks the box at index and unchecks the last one. It returns the
+// newly checked box's ID or -1 if it was checked already.
+func (group *checkBoxGroup) check(index int) (actionID int) {
+	if index == group.checkedIndex {
+		return -1
+	}
+	group.boxes[group.checkedIndex].setChecked(false)
+	group.boxes[index].setChecked(true)
+	group.checkedIndex = index
+	return group.boxes[index].id
+}
+
+func (group *checkBoxGroup) canFocus() bool  { return len(group.boxes) > 0 }
+func (group *checkBoxGroup) setFocus(bool)   {}
+func (group *checkBoxGroup) focusRect() rect { return group.bounds() }
+
+// children returns nothing, the boxes in a group do not get the focus on their
+// own, the arrow keys move between them.
+func (group *checkBoxGroup) children() []guiElement { return nil }
+
 // tab sheet
 
 func newTabSheet(captionH int, tabs ...*tab) *tabSheet {
@@ -577,16 +1054,19 @@ func (s *tabSheet) setBounds(bounds rect) {
 }
 
 func (s *tabSheet) draw(g *graphics) {
-	activeColor := s.visibleTabs[s.activeIndex].color
+	activeColor := s.visibleTabs[s.activeIndex].color()
 	activeColor[3] *= 0.85
 	g.rect(s.x, s.y+s.captionH, s.w, s.h-s.captionH, activeColor)
 	for i, tab := range s.visibleTabs {
 		b := s.captionBounds[i]
-		color := tab.color
+		color := tab.color()
 		if i == s.activeIndex {
 			color = activeColor
 		}
 		g.rect(b.x, b.y, b.w, b.h, color)
+		if tab.decorate != nil {
+			tab.decorate(g, b)
+		}
 	}
 	s.visibleTabs[s.activeIndex].content.draw(g)
 }
@@ -609,22 +1089,135 @@ func (s *tabSheet) runeTyped(r rune) {
 	s.visibleTabs[s.activeIndex].content.runeTyped(r)
 }
 
-func (s *tabSheet) keyPressed(key glfw.Key) {
-	s.visibleTabs[s.activeIndex].content.keyPressed(key)
+// keyPressed switches to the tab on the left or right for the arrow keys.
+func (s *tabSheet) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	switch key {
+	case glfw.KeyLeft:
+		if s.activeIndex > 0 {
+			s.activeIndex--
+		}
+		return -1
+	case glfw.KeyRight:
+		if s.activeIndex < len(s.visibleTabs)-1 {
+			s.activeIndex++
+		}
+		return -1
+	}
+	return s.visibleTabs[s.activeIndex].content.keyPressed(key, mods)
+}
+
+func (s *tabSheet) canFocus() bool  { return len(s.visibleTabs) > 1 }
+func (s *tabSheet) setFocus(bool)   {}
+func (s *tabSheet) focusRect() rect { return s.captionBounds[s.activeIndex] }
+
+func (s *tabSheet) children() []guiElement {
+	return []guiElement{s.visibleTabs[s.activeIndex].content}
 }
 
 // tab
 
 func newTab(color [4]float32, content guiElement, visible bool) *tab {
-	return &tab{content, color, visible}
+	return &tab{
+		content: content,
+		color:   func() [4]float32 { return color },
+		visible: visible,
+	}
 }
 
 type tab struct {
 	content guiElement
-	color   [4]float32
-	visible bool
+	// color returns the color of the caption and the content background
+	color func() [4]float32
+	// decorate draws on top of the caption, if it is nil the caption only
+	// shows the color
+	decorate func(g *graphics, caption rect)
+	visible  bool
+}
+
+func (t *tab) onColor(color func() [4]float32) {
+	t.color = color
+}
+
+func (t *tab) onDecorate(decorate func(g *graphics, caption rect)) {
+	t.decorate = decorate
+}
+
+// label
+
+// newLabel creates a label that shows the text returned by the given function.
+// It is called every time the label is drawn so the label can show changing
+// information. Labels with empty text are not drawn.
+func newLabel(bounds rect, text func() string) *label {
+	return &label{rect: bounds, text: text, fontColor: &menuFontColor}
+}
+
+type label struct {
+	rect
+	text func() string
+	// fontColor points to the color of the text, it usually is one of the
+	// theme's colors so it changes with the theme
+	fontColor *[4]float32
+	// backColor returns the color to draw behind the text, if it is nil the
+	// normal menu color is used
+	backColor func() [4]float32
+	// clicked is called when the label is clicked, if it is nil the label
+	// ignores clicks
+	clicked func()
+	hot     bool
 }
 
+func (l *label) bounds() rect          { return l.rect }
+func (l *label) setBounds(bounds rect) { l.rect = bounds }
+
+func (l *label) onBackColor(color func() [4]float32) {
+	l.backColor = color
+}
+
+func (l *label) onClick(action func()) {
+	l.clicked = action
+}
+
+func (l *label) draw(g *graphics) {
+	text := l.text()
+	if text == "" {
+		return
+	}
+	color := menuColdBackColor
+	if l.backColor != nil {
+		color = l.backColor()
+	}
+	if l.hot {
+		color = menuHotBackColor
+	}
+	g.rect(l.x, l.y, l.w, l.h, color)
+	g.writeTextLineCenteredInRect(text, l.rect, *l.fontColor)
+}
+
+func (l *label) mouseMovedTo(x, y int) {
+	l.hot = l.clicked != nil && l.contains(x, y)
+}
+
+func (l *label) click(x, y int) (actionID int) {
+	if l.clicked != nil && l.contains(x, y) && l.text() != "" {
+		l.clicked()
+	}
+	return -1
+}
+
+func (*label) runeTyped(rune) {}
+
+func (l *label) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	if l.canFocus() && isActivationKey(key) {
+		l.clicked()
+	}
+	return -1
+}
+
+// canFocus returns true for labels that can be clicked.
+func (l *label) canFocus() bool  { return l.clicked != nil && l.text() != "" }
+func (l *label) setFocus(bool)   {}
+func (l *label) focusRect() rect { return l.rect }
+
 // spacer
 
 func newSpacer(bounds rect) *spacer {
@@ -641,4 +1234,141 @@ func (spacer) draw(*graphics)                {}
 func (spacer) mouseMovedTo(x, y int)         {}
 func (spacer) click(x, y int) (actionID int) { return -1 }
 func (spacer) runeTyped(rune)                {}
-func (spacer) keyPressed(glfw.Key)           {}
+
+func (spacer) keyPressed(glfw.Key, glfw.ModifierKey) (actionID int) { return -1 }
+
+// text log
+
+// newTextLog creates a list of text lines with the newest at the bottom. count
+// returns how many lines there are and line returns one of them, new lines are
+// only ever added at the end. Lines that are too long are wrapped, when there
+// are more lines than fit, the older ones can be scrolled to.
+func newTextLog(bounds rect, font textSizer, count func() int, line func(i int) string) *textLog {
+	return &textLog{rect: bounds, font: font, count: count, line: line}
+}
+
+type textLog struct {
+	rect
+	font  textSizer
+	count func() int
+	line  func(i int) string
+	// scroll is the number of lines hidden below the bottom, at 0 the newest
+	// lines are visible
+	scroll int
+	// wrapped are the wrapped lines for the first wrappedCount lines, they
+	// are wrapped again when the width or the language changes
+	wrapped      []string
+	wrappedCount int
+	wrappedW     int
+	wrappedLang  lang.Language
+}
+
+const (
+	textLogMargin = 10
+	textLogLineH  = 50
+)
+
+func (l *textLog) bounds() rect          { return l.rect }
+func (l *textLog) setBounds(bounds rect) { l.rect = bounds }
+
+func (l *textLog) draw(g *graphics) {
+	g.rect(l.x, l.y, l.w, l.h, textLogBackColor)
+	lines := l.wrappedLines()
+	visible := l.visibleLineCount()
+	l.clampScroll(len(lines)) // lines may have come in since the last frame
+	end := len(lines) - l.scroll
+	start := end - visible
+	if start < 0 {
+		start = 0
+	}
+	y := l.y + textLogMargin
+	for _, line := range lines[start:end] {
+		g.writeLeftAlignedVerticallyCenteredAt(
+			line, l.x+textLogMargin, y+textLogLineH/2, textLogFontColor)
+		y += textLogLineH
+	}
+
+	if len(lines) > visible {
+		// the scroll bar shows which part of the log is visible
+		const barW = 6
+		trackH := l.h - 2*textLogMargin
+		barH := trackH * visible / len(lines)
+		barY := l.y + textLogMargin + trackH*start/len(lines)
+		g.rect(l.x+l.w-barW-2, barY, barW, barH, [4]float32{0.3, 0.2, 0.1, 0.8})
+	}
+}
+
+func (l *textLog) visibleLineCount() int {
+	return (l.h - 2*textLogMargin) / textLogLineH
+}
+
+func (l *textLog) wrappedLines() []string {
+	n := l.count()
+	if n < l.wrappedCount || l.w != l.wrappedW || lang.CurrentLanguage != l.wrappedLang {
+		// a new game started or the lines look different now
+		l.wrapped, l.wrappedCount = nil, 0
+		l.wrappedW, l.wrappedLang = l.w, lang.CurrentLanguage
+	}
+	for ; l.wrappedCount < n; l.wrappedCount++ {
+		line := l.line(l.wrappedCount)
+		l.wrapped = append(l.wrapped, wrapText(line, l.w-3*textLogMargin, l.font)...)
+	}
+	return l.wrapped
+}
+
+// scrollBy scrolls up to older lines for positive n and down to newer lines for
+// negative n.
+func (l *textLog) scrollBy(n int) {
+	l.scroll += n
+	l.clampScroll(len(l.wrappedLines()))
+}
+
+func (l *textLog) clampScroll(lineCount int) {
+	maxScroll := lineCount - l.visibleLineCount()
+	if l.scroll > maxScroll {
+		l.scroll = maxScroll
+	}
+	if l.scroll < 0 {
+		l.scroll = 0
+	}
+}
+
+func (l *textLog) mouseMovedTo(x, y int)         {}
+func (l *textLog) click(x, y int) (actionID int) { return -1 }
+func (l *textLog) runeTyped(rune)                {}
+
+func (l *textLog) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	if key == glfw.KeyPageUp {
+		l.scrollBy(l.visibleLineCount())
+	}
+	if key == glfw.KeyPageDown {
+		l.scrollBy(-l.visibleLineCount())
+	}
+	return -1
+}
+
+// wrapText breaks the text at spaces into lines that are at most width wide.
+// Single words that are wider stay on a line of their own.
+func wrapText(text string, width int, font textSizer) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if w, _ := font.TextSize(candidate); w > width && line != "" {
+			lines = append(lines, line)
+			line = word
+		} else {
+			line = candidate
+		}
+	}
+	return append(lines, line)
+}
+
+// isActivationKey returns true for the keys that activate the focused element
+// like a click would.
+func isActivationKey(key glfw.Key) bool {
+	return key == glfw.KeyEnter || key == glfw.KeyKPEnter || key == glfw.KeySpace
+}
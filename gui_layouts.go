@@ -128,3 +128,130 @@ func (l *topLeftLayout) relayout(in rect) {
 		y += b.h
 	}
 }
+
+// The horizontal flow layout puts all elements directly next to each other and
+// centers them vertically on each column. The whole block will be horizontally
+// centered as well.
+
+func newHorizontalFlowLayout(horizontalSpaceBetweenElements int) *horizontalFlowLayout {
+	return &horizontalFlowLayout{layoutBase{}, horizontalSpaceBetweenElements}
+}
+
+type horizontalFlowLayout struct {
+	layoutBase
+	xMargin int
+}
+
+func (l *horizontalFlowLayout) relayout(in rect) {
+	width := 0
+	if len(l.items) > 0 {
+		width = l.items[0].bounds().w
+		for i := 1; i < len(l.items); i++ {
+			width += l.xMargin + l.items[i].bounds().w
+		}
+	}
+
+	x := in.x + (in.w-width)/2
+	for _, item := range l.items {
+		b := item.bounds()
+		item.setBounds(rect{x, in.y + (in.h-b.h)/2, b.w, b.h})
+		x += b.w + l.xMargin
+	}
+}
+
+// The grid layout puts its elements into rows of the given number of columns,
+// from left to right and top to bottom. All cells are as big as the biggest
+// element and every element is centered in its cell. The whole grid is centered
+// in the destination rectangle.
+
+func newGridLayout(columns, spaceBetweenCells int) *gridLayout {
+	return &gridLayout{layoutBase{}, columns, spaceBetweenCells}
+}
+
+type gridLayout struct {
+	layoutBase
+	columns int
+	margin  int
+}
+
+func (l *gridLayout) relayout(in rect) {
+	if len(l.items) == 0 {
+		return
+	}
+	cellW, cellH := 0, 0
+	for _, item := range l.items {
+		b := item.bounds()
+		if b.w > cellW {
+			cellW = b.w
+		}
+		if b.h > cellH {
+			cellH = b.h
+		}
+	}
+	columns := l.columns
+	if len(l.items) < columns {
+		columns = len(l.items)
+	}
+	rows := (len(l.items) + columns - 1) / columns
+	width := columns*cellW + (columns-1)*l.margin
+	height := rows*cellH + (rows-1)*l.margin
+
+	left, top := in.x+(in.w-width)/2, in.y+(in.h-height)/2
+	for i, item := range l.items {
+		b := item.bounds()
+		x := left + (i%columns)*(cellW+l.margin)
+		y := top + (i/columns)*(cellH+l.margin)
+		item.setBounds(rect{x + (cellW-b.w)/2, y + (cellH-b.h)/2, b.w, b.h})
+	}
+}
+
+// anchor is a combination of edges of the destination rectangle.
+type anchor int
+
+const (
+	anchorLeft anchor = 1 << iota
+	anchorRight
+	anchorTop
+	anchorBottom
+)
+
+// The anchor layout moves the bounding box of its elements to the given edges
+// of the destination rectangle, xMargin and yMargin away from them. Along an
+// axis where neither or both edges are given, the elements are centered. Laid
+// out in the whole HUD area, elements stay in their corners when the window
+// size changes.
+
+func newAnchorLayout(edges anchor, xMargin, yMargin int) *anchorLayout {
+	return &anchorLayout{layoutBase{}, edges, xMargin, yMargin}
+}
+
+type anchorLayout struct {
+	layoutBase
+	edges            anchor
+	xMargin, yMargin int
+}
+
+func (l *anchorLayout) relayout(in rect) {
+	bounds := boundingBox(boundingBoxableItems(l.items))
+	x := alignAt(l.edges&anchorLeft != 0, l.edges&anchorRight != 0,
+		in.x, in.w, bounds.w, l.xMargin)
+	y := alignAt(l.edges&anchorTop != 0, l.edges&anchorBottom != 0,
+		in.y, in.h, bounds.h, l.yMargin)
+	dx, dy := x-bounds.x, y-bounds.y
+	for _, item := range l.items {
+		b := item.bounds()
+		item.setBounds(rect{b.x + dx, b.y + dy, b.w, b.h})
+	}
+}
+
+// alignAt returns where something of the given size starts along one axis when
+// it sticks to the start or end of the available space, or is centered in it.
+func alignAt(toStart, toEnd bool, start, space, size, margin int) int {
+	if toStart && !toEnd {
+		return start + margin
+	}
+	if toEnd && !toStart {
+		return start + space - margin - size
+	}
+	return start + (space-size)/2
+}
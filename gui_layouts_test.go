@@ -0,0 +1,148 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+type box struct{ rect }
+
+func (b *box) bounds() rect          { return b.rect }
+func (b *box) setBounds(bounds rect) { b.rect = bounds }
+
+func laidOut(l layout, in rect, sizes ...rect) []rect {
+	boxes := make([]*box, len(sizes))
+	for i := range sizes {
+		boxes[i] = &box{sizes[i]}
+		l.addElement(boxes[i])
+	}
+	l.relayout(in)
+	result := make([]rect, len(boxes))
+	for i, b := range boxes {
+		result[i] = b.rect
+	}
+	return result
+}
+
+func checkRects(t *testing.T, name string, got, want []rect) {
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: element %d is at %v but should be at %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+func TestHorizontalFlowLayoutPutsElementsNextToEachOther(t *testing.T) {
+	got := laidOut(newHorizontalFlowLayout(10), rect{0, 0, 200, 100},
+		rect{0, 0, 50, 20}, rect{0, 0, 30, 40})
+	checkRects(t, "horizontal flow", got, []rect{{55, 40, 50, 20}, {115, 30, 30, 40}})
+}
+
+func TestGridLayoutCentersElementsInEqualCells(t *testing.T) {
+	got := laidOut(newGridLayout(2, 10), rect{0, 0, 100, 100},
+		rect{0, 0, 40, 20}, rect{0, 0, 20, 20}, rect{0, 0, 40, 30})
+	checkRects(t, "grid", got, []rect{
+		{5, 20, 40, 20}, {65, 20, 20, 20},
+		{5, 55, 40, 30},
+	})
+}
+
+func TestAnchoredElementsFollowTheWindowSize(t *testing.T) {
+	cam := testCamera()
+	corner := &box{rect{0, 0, 100, 50}}
+	bottom := &box{rect{0, 0, 200, 50}}
+	cornerLayout := newAnchorLayout(anchorRight|anchorTop, 10, 20)
+	cornerLayout.addElement(corner)
+	bottomLayout := newAnchorLayout(anchorBottom, 0, 5)
+	bottomLayout.addElement(bottom)
+
+	for _, size := range [][2]int{{800, 600}, {1600, 600}, {600, 1200}} {
+		cam.windowSizeChangedTo(size[0], size[1])
+		area := cam.hudBounds()
+		cornerLayout.relayout(area)
+		bottomLayout.relayout(area)
+
+		if right := area.x + area.w - 10; corner.x+corner.w != right || corner.y != area.y+20 {
+			t.Errorf("%v: corner element is at %v in %v", size, corner.rect, area)
+		}
+		if bottom.y+bottom.h != area.y+area.h-5 ||
+			bottom.x+bottom.w/2 != area.x+area.w/2 {
+			t.Errorf("%v: bottom element is at %v in %v", size, bottom.rect, area)
+		}
+	}
+}
+
+func TestHUDPartsDoNotOverlap(t *testing.T) {
+	g, err := newSoftwareGraphics(image.NewRGBA(screenBounds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := &gameUI{game: goldenGame(), camera: testCamera()}
+	ui.hud = newHUD(g, ui)
+	for _, scale := range []float64{minUIScale, maxUIScale} {
+		ui.camera.setUIScale(scale)
+		ui.hud.setBounds(ui.camera.hudBounds())
+		h := ui.hud
+		if menu := h.buyMenu.mainRect(); menu.y+menu.h > h.eventLog.y {
+			t.Errorf("scale %v: buy menu %v overlaps the event log %v", scale, menu, h.eventLog.rect)
+		}
+		if h.dice.y < h.instruction.y+h.instruction.h {
+			t.Errorf("scale %v: dice %v overlap the instruction bar %v", scale, h.dice.rect, h.instruction.rect)
+		}
+	}
+}
+
+func TestPlayerPanelsCollapseInsteadOfCoveringTheBoard(t *testing.T) {
+	board := renderBoard(t, goldenGame(), closed)
+	water := board.RGBAAt(screenBounds.Min.X, screenBounds.Min.Y)
+	g, err := newSoftwareGraphics(image.NewRGBA(screenBounds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a window with the aspect of the screen bounds shows the HUD in game
+	// coordinates
+	cam := newCamera()
+	cam.WindowWidth, cam.WindowHeight = screenBounds.Dx(), screenBounds.Dy()
+	cam.recalcOrthoBorders()
+	ui := &gameUI{game: goldenGame(), camera: cam}
+	ui.hud = newHUD(g, ui)
+	panels := ui.hud.playerPanels
+
+	ui.hud.update(ui)
+	if !panels.collapsed {
+		t.Fatal("the panels are not collapsed although they would cover the board")
+	}
+	shown := panels.shownRect()
+	for y := shown.y; y < shown.y+shown.h; y++ {
+		for x := shown.x; x < shown.x+shown.w; x++ {
+			if board.RGBAAt(x, y) != water {
+				t.Fatalf("the collapsed panels %v cover the board at %d,%d", shown, x, y)
+			}
+		}
+	}
+	centerX, centerY := shown.x+shown.w/2, shown.y+shown.h/2
+	if !ui.hud.covers(centerX, centerY) {
+		t.Error("the HUD does not cover the collapsed panels")
+	}
+
+	panels.toggle()
+	ui.hud.update(ui)
+	if panels.collapsed {
+		t.Error("a click does not expand the panels")
+	}
+	if expanded := panels.shownRect(); !ui.hud.covers(expanded.x+1, expanded.y+expanded.h-1) {
+		t.Error("the HUD does not cover the expanded panels")
+	}
+	panels.toggle()
+
+	// in a wide window there is room beside the board
+	cam.WindowWidth *= 2
+	cam.recalcOrthoBorders()
+	ui.hud.setBounds(cam.hudBounds())
+	ui.hud.update(ui)
+	boardRight, _ := ui.boardToHUD(gameW, 0)
+	if panels.collapsed || panels.shownRect().x < boardRight {
+		t.Errorf("the panels %v are not shown beside the board, it ends at %d",
+			panels.shownRect(), boardRight)
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/gonutz/settlers/lang"
+)
+
+var (
+	tableBackColor     = [4]float32{0.8, 0.6, 0.5, 0.8}
+	tableHeaderColor   = [4]float32{0.6, 0.4, 0.3, 0.9}
+	tableHotRowColor   = [4]float32{0.9, 0.75, 0.6, 0.9}
+	tableSelectedColor = [4]float32{0.71, 0.4, 0.31, 1}
+	tableFontColor     = [4]float32{0, 0, 0, 1}
+	tableScrollColor   = [4]float32{0.3, 0.2, 0.1, 0.8}
+)
+
+var (
+	tableRowH   = 60
+	tableMargin = 10
+)
+
+const tableScrollW = 6
+
+// newTable creates a table with a header row and the given columns. The widths
+// of the columns are relative to each other, the table is divided among them.
+// rows is called every time the table is drawn, so the table always shows the
+// current data. If there are more rows than fit, the mouse wheel and the arrow
+// keys scroll through them.
+func newTable(bounds rect, font textSizer, headers []lang.Item, widths []int, rows func() [][]string) *table {
+	return &table{
+		rect:     bounds,
+		font:     font,
+		headers:  headers,
+		widths:   widths,
+		rows:     rows,
+		selected: -1,
+		hotRow:   -1,
+	}
+}
+
+// newList creates a table with one column and no header.
+func newList(bounds rect, font textSizer, items func() []string) *table {
+	return newTable(bounds, font, nil, []int{1}, func() [][]string {
+		var rows [][]string
+		for _, item := range items() {
+			rows = append(rows, []string{item})
+		}
+		return rows
+	})
+}
+
+type table struct {
+	rect
+	font    textSizer
+	headers []lang.Item
+	widths  []int
+	rows    func() [][]string
+	// scroll is the index of the first visible row
+	scroll int
+	// selected is the index of the selected row or -1 if none is selected
+	selected int
+	hotRow   int
+	// selectAction is called when the user selects a row, activateAction
+	// when the selected row is clicked again or Enter is pressed
+	selectAction   func(row int)
+	activateAction func(row int)
+}
+
+func (t *table) onSelect(action func(row int))   { t.selectAction = action }
+func (t *table) onActivate(action func(row int)) { t.activateAction = action }
+
+func (t *table) bounds() rect          { return t.rect }
+func (t *table) setBounds(bounds rect) { t.rect = bounds }
+
+func (t *table) draw(g *graphics) {
+	g.rect(t.x, t.y, t.w, t.h, tableBackColor)
+	rows := t.rows()
+	t.clamp(len(rows)) // rows may have changed since the last frame
+	columns := t.columnRects()
+
+	y := t.y
+	if t.headers != nil {
+		g.rect(t.x, y, t.w, tableRowH, tableHeaderColor)
+		for i, header := range t.headers {
+			t.drawCell(g, lang.Get(header), columns[i], y)
+		}
+		y += tableRowH
+	}
+
+	visible := t.visibleRowCount()
+	for i := t.scroll; i < len(rows) && i < t.scroll+visible; i++ {
+		if i == t.selected {
+			g.rect(t.x, y, t.w, tableRowH, tableSelectedColor)
+		} else if i == t.hotRow {
+			g.rect(t.x, y, t.w, tableRowH, tableHotRowColor)
+		}
+		for c, cell := range rows[i] {
+			if c < len(columns) {
+				t.drawCell(g, cell, columns[c], y)
+			}
+		}
+		y += tableRowH
+	}
+
+	if len(rows) > visible {
+		// the scroll bar shows which part of the rows is visible
+		top := t.rowsTop()
+		trackH := t.y + t.h - top
+		barH := trackH * visible / len(rows)
+		barY := top + trackH*t.scroll/len(rows)
+		g.rect(t.x+t.w-tableScrollW-2, barY, tableScrollW, barH, tableScrollColor)
+	}
+}
+
+func (t *table) drawCell(g *graphics, text string, column rect, y int) {
+	text = fitText(text, column.w-2*tableMargin, t.font)
+	g.writeLeftAlignedVerticallyCenteredAt(
+		text, column.x+tableMargin, y+tableRowH/2, tableFontColor)
+}
+
+// columnRects returns the x position and width of every column, the y and h
+// are not used.
+func (t *table) columnRects() []rect {
+	total := 0
+	for _, w := range t.widths {
+		total += w
+	}
+	available := t.w - tableScrollW - 4
+	columns := make([]rect, len(t.widths))
+	x := t.x
+	for i, w := range t.widths {
+		columns[i] = rect{x: x, w: available * w / total}
+		x += columns[i].w
+	}
+	return columns
+}
+
+func (t *table) rowsTop() int {
+	if t.headers != nil {
+		return t.y + tableRowH
+	}
+	return t.y
+}
+
+func (t *table) visibleRowCount() int {
+	return (t.y + t.h - t.rowsTop()) / tableRowH
+}
+
+// rowAt returns the index of the row at y or -1 if there is none.
+func (t *table) rowAt(x, y, rowCount int) int {
+	if !t.contains(x, y) || y < t.rowsTop() {
+		return -1
+	}
+	row := t.scroll + (y-t.rowsTop())/tableRowH
+	if row >= rowCount || row >= t.scroll+t.visibleRowCount() {
+		return -1
+	}
+	return row
+}
+
+func (t *table) mouseMovedTo(x, y int) {
+	t.hotRow = t.rowAt(x, y, len(t.rows()))
+}
+
+// click selects the clicked row, clicking the selected row activates it.
+func (t *table) click(x, y int) (actionID int) {
+	row := t.rowAt(x, y, len(t.rows()))
+	if row == -1 {
+		return -1
+	}
+	if row == t.selected {
+		t.activate()
+	} else {
+		t.selectRow(row)
+	}
+	return -1
+}
+
+func (t *table) runeTyped(rune) {}
+
+func (t *table) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	rowCount := len(t.rows())
+	if rowCount == 0 {
+		return -1
+	}
+	switch key {
+	case glfw.KeyUp:
+		t.selectRow(t.selected - 1)
+	case glfw.KeyDown:
+		t.selectRow(t.selected + 1)
+	case glfw.KeyPageUp:
+		t.selectRow(t.selected - t.visibleRowCount())
+	case glfw.KeyPageDown:
+		t.selectRow(t.selected + t.visibleRowCount())
+	case glfw.KeyHome:
+		t.selectRow(0)
+	case glfw.KeyEnd:
+		t.selectRow(rowCount - 1)
+	default:
+		if isActivationKey(key) {
+			t.activate()
+		}
+	}
+	return -1
+}
+
+func (t *table) canFocus() bool  { return len(t.rows()) > 0 }
+func (t *table) setFocus(bool)   {}
+func (t *table) focusRect() rect { return t.rect }
+
+// selectRow selects the row, clamped to the existing ones, and scrolls it into
+// view.
+func (t *table) selectRow(row int) {
+	rowCount := len(t.rows())
+	if row >= rowCount {
+		row = rowCount - 1
+	}
+	if row < 0 {
+		row = 0
+	}
+	if rowCount == 0 || row == t.selected {
+		return
+	}
+	t.selected = row
+	if row < t.scroll {
+		t.scroll = row
+	}
+	if visible := t.visibleRowCount(); row >= t.scroll+visible {
+		t.scroll = row - visible + 1
+	}
+	if t.selectAction != nil {
+		t.selectAction(row)
+	}
+}
+
+func (t *table) activate() {
+	if t.selected != -1 && t.activateAction != nil {
+		t.activateAction(t.selected)
+	}
+}
+
+// scrollBy scrolls up to earlier rows for positive n and down to later rows for
+// negative n.
+func (t *table) scrollBy(n int) {
+	t.scroll -= n
+	t.clamp(len(t.rows()))
+}
+
+// clamp keeps the scroll position and selection inside the existing rows.
+func (t *table) clamp(rowCount int) {
+	if t.scroll > rowCount-t.visibleRowCount() {
+		t.scroll = rowCount - t.visibleRowCount()
+	}
+	if t.scroll < 0 {
+		t.scroll = 0
+	}
+	if t.selected >= rowCount {
+		t.selected = -1
+	}
+}
+
+// fitText shortens the text with "..." at the end so it is at most width wide.
+func fitText(text string, width int, font textSizer) string {
+	if w, _ := font.TextSize(text); w <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		shortened := string(runes) + "..."
+		if w, _ := font.TextSize(shortened); w <= width {
+			return shortened
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/gonutz/settlers/lang"
+	"strconv"
+	"testing"
+)
+
+func numberedItems(n int) func() []string {
+	return func() []string {
+		items := make([]string, n)
+		for i := range items {
+			items[i] = "item " + strconv.Itoa(i)
+		}
+		return items
+	}
+}
+
+func TestClickingAListSelectsAndActivatesRows(t *testing.T) {
+	list := newList(rect{0, 0, 400, 3 * tableRowH}, testGraphics(), numberedItems(10))
+	selected, activated := -1, -1
+	list.onSelect(func(row int) { selected = row })
+	list.onActivate(func(row int) { activated = row })
+
+	list.click(10, tableRowH+10)
+	if selected != 1 || activated != -1 {
+		t.Errorf("first click selected %d and activated %d", selected, activated)
+	}
+	list.click(10, tableRowH+10)
+	if activated != 1 {
+		t.Errorf("second click activated %d", activated)
+	}
+}
+
+func TestArrowKeysScrollTheSelectionIntoView(t *testing.T) {
+	list := newList(rect{0, 0, 400, 3 * tableRowH}, testGraphics(), numberedItems(10))
+	for i := 0; i < 5; i++ {
+		list.keyPressed(glfw.KeyDown, 0)
+	}
+	if list.selected != 4 || list.scroll != 2 {
+		t.Errorf("selected %d, scrolled to %d", list.selected, list.scroll)
+	}
+	list.keyPressed(glfw.KeyEnd, 0)
+	if list.selected != 9 || list.scroll != 7 {
+		t.Errorf("End selected %d, scrolled to %d", list.selected, list.scroll)
+	}
+	list.keyPressed(glfw.KeyHome, 0)
+	if list.selected != 0 || list.scroll != 0 {
+		t.Errorf("Home selected %d, scrolled to %d", list.selected, list.scroll)
+	}
+}
+
+func TestMouseWheelScrollsTheTableUnderTheCursor(t *testing.T) {
+	rows := func() [][]string {
+		var rows [][]string
+		for _, item := range numberedItems(10)() {
+			rows = append(rows, []string{item, "x"})
+		}
+		return rows
+	}
+	table := newTable(rect{0, 0, 400, 4 * tableRowH}, testGraphics(),
+		[]lang.Item{lang.Name, lang.IP}, []int{2, 1}, rows)
+	m := newFocusManager(newWindow(rect{}, newDummyLayout(), table))
+
+	m.scrollAt(10, 10, -2)
+	if table.scroll != 2 {
+		t.Errorf("scrolled down to %d", table.scroll)
+	}
+	m.scrollAt(10, 10, -100)
+	if table.scroll != 7 {
+		t.Errorf("scrolled down past the end to %d", table.scroll)
+	}
+	m.scrollAt(500, 10, 3)
+	if table.scroll != 7 {
+		t.Errorf("wheel beside the table scrolled to %d", table.scroll)
+	}
+
+	// the header row does not belong to the rows
+	table.click(10, 10)
+	if table.selected != -1 {
+		t.Errorf("clicking the header selected row %d", table.selected)
+	}
+	table.click(10, tableRowH+10)
+	if table.selected != 7 {
+		t.Errorf("clicking the first visible row selected %d", table.selected)
+	}
+}
+
+func TestFitTextShortensLongTexts(t *testing.T) {
+	font := testGraphics()
+	charW, _ := font.TextSize("x")
+	if got := fitText("abc", 3*charW, font); got != "abc" {
+		t.Errorf("fitting text was changed to %q", got)
+	}
+	if got := fitText("abcdefgh", 6*charW, font); got != "abc..." {
+		t.Errorf("long text was shortened to %q", got)
+	}
+}
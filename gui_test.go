@@ -0,0 +1,222 @@
+package main
+
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/gonutz/settlers/lang"
+	"image"
+	"testing"
+)
+
+func testGraphics() *graphics {
+	return &graphics{renderer: newSoftwareRenderer(image.NewRGBA(screenBounds), nil)}
+}
+
+func TestTabMovesFocusThroughVisibleEnabledElements(t *testing.T) {
+	first := newButton(lang.OK, rect{0, 0, 100, 50}, 1)
+	disabled := newButton(lang.OK, rect{0, 50, 100, 50}, 2)
+	disabled.setEnabled(false)
+	g := testGraphics()
+	text := newTextBox(lang.Name, rect{0, 100, 400, 50}, g, nil)
+	hidden := newWindow(rect{}, newDummyLayout(), newButton(lang.OK, rect{0, 150, 100, 50}, 3))
+	hidden.setVisible(false)
+	m := newFocusManager(newWindow(rect{}, newDummyLayout(), first, disabled, text), hidden)
+
+	m.keyPressed(glfw.KeyTab, 0)
+	if m.focused != first {
+		t.Fatalf("first Tab focused %v", m.focused)
+	}
+	m.keyPressed(glfw.KeyTab, 0)
+	if m.focused != text || !text.focused {
+		t.Fatalf("second Tab focused %v", m.focused)
+	}
+	m.keyPressed(glfw.KeyTab, 0)
+	if m.focused != first || text.focused {
+		t.Fatalf("Tab did not wrap around, focused %v", m.focused)
+	}
+	m.keyPressed(glfw.KeyTab, glfw.ModShift)
+	if m.focused != text {
+		t.Fatalf("Shift+Tab focused %v", m.focused)
+	}
+
+	m.draw(g)
+	m.runeTyped('A')
+	if text.text != "A" {
+		t.Errorf("focused text box got %q", text.text)
+	}
+	text.setEnabled(false)
+	m.runeTyped('B')
+	if m.focused != nil || text.text != "A" {
+		t.Errorf("disabled text box kept the focus and got %q", text.text)
+	}
+}
+
+func TestEnterAndSpaceActivateTheFocusedElement(t *testing.T) {
+	b := newButton(lang.OK, rect{0, 0, 100, 50}, 7)
+	c := newCheckBox(lang.OK, rect{0, 50, 100, 50}, 8)
+	m := newFocusManager(b, c)
+
+	m.keyPressed(glfw.KeyTab, 0)
+	if action := m.keyPressed(glfw.KeyEnter, 0); action != 7 {
+		t.Errorf("Enter on button returned %d", action)
+	}
+	m.keyPressed(glfw.KeyTab, 0)
+	if action := m.keyPressed(glfw.KeySpace, 0); action != 8 || !c.checked {
+		t.Errorf("Space on check box returned %d, checked %v", action, c.checked)
+	}
+	if action := m.keyPressed(glfw.KeySpace, 0); action != -1 || c.checked {
+		t.Errorf("unchecking returned %d, checked %v", action, c.checked)
+	}
+}
+
+func TestArrowKeysMoveInsideCheckBoxGroupsAndTabSheets(t *testing.T) {
+	boxes := []*checkBox{
+		newCheckBox(lang.OK, rect{0, 0, 100, 50}, 1),
+		newCheckBox(lang.OK, rect{0, 0, 100, 50}, 2),
+		newCheckBox(lang.OK, rect{0, 0, 100, 50}, 3),
+	}
+	group := newCheckBoxGroup(boxes...)
+	sheet := newTabSheet(50,
+		newTab([4]float32{}, newWindow(rect{0, 100, 300, 100}, newDummyLayout()), true),
+		newTab([4]float32{}, newWindow(rect{0, 100, 300, 100}, newDummyLayout()), true),
+	)
+	m := newFocusManager(group, sheet)
+
+	m.keyPressed(glfw.KeyTab, 0)
+	if m.focused != group {
+		t.Fatalf("group did not get the focus but %v", m.focused)
+	}
+	if action := m.keyPressed(glfw.KeyDown, 0); action != 2 || !boxes[1].checked || boxes[0].checked {
+		t.Errorf("Down returned %d", action)
+	}
+	if action := m.keyPressed(glfw.KeyUp, 0); action != 1 {
+		t.Errorf("Up returned %d", action)
+	}
+	if action := m.keyPressed(glfw.KeyUp, 0); action != 3 || !boxes[2].checked {
+		t.Errorf("Up did not wrap around, returned %d", action)
+	}
+
+	m.keyPressed(glfw.KeyTab, 0)
+	m.keyPressed(glfw.KeyRight, 0)
+	if sheet.activeIndex != 1 {
+		t.Errorf("Right switched to tab %d", sheet.activeIndex)
+	}
+	m.keyPressed(glfw.KeyRight, 0)
+	m.keyPressed(glfw.KeyLeft, 0)
+	if sheet.activeIndex != 0 {
+		t.Errorf("Left switched to tab %d", sheet.activeIndex)
+	}
+}
+
+func TestClickingATextBoxFocusesIt(t *testing.T) {
+	text := newTextBox(lang.Name, rect{0, 0, 400, 50}, testGraphics(), nil)
+	m := newFocusManager(text)
+	m.click(10, 10)
+	if !text.focused {
+		t.Fatal("clicked text box has no focus")
+	}
+	m.click(10, 100)
+	if text.focused || m.focused != nil {
+		t.Error("clicking beside the text box did not take the focus away")
+	}
+}
+
+type fakeClipboard struct{ text string }
+
+func (c *fakeClipboard) SetClipboardString(text string)      { c.text = text }
+func (c *fakeClipboard) GetClipboardString() (string, error) { return c.text, nil }
+
+func TestTextBoxEditsAtTheCaret(t *testing.T) {
+	clip := &fakeClipboard{}
+	text := newTextBox(lang.IP, rect{0, 0, 600, 50}, testGraphics(), clip)
+	text.text = "192.168.0.1"
+	text.setFocus(true)
+	press := func(key glfw.Key, mods glfw.ModifierKey) { text.keyPressed(key, mods) }
+
+	press(glfw.KeyHome, 0)
+	for i := 0; i < 5; i++ {
+		press(glfw.KeyRight, 0)
+	}
+	press(glfw.KeyDelete, 0)
+	text.runeTyped('7')
+	if text.text != "192.178.0.1" {
+		t.Errorf("editing in the middle gave %q", text.text)
+	}
+
+	press(glfw.KeyEnd, 0)
+	press(glfw.KeyLeft, glfw.ModShift)
+	press(glfw.KeyLeft, glfw.ModShift)
+	press(glfw.KeyX, glfw.ModControl)
+	if text.text != "192.178.0" || clip.text != ".1" {
+		t.Errorf("cutting the selection left %q and copied %q", text.text, clip.text)
+	}
+	press(glfw.KeyHome, 0)
+	press(glfw.KeyV, glfw.ModControl)
+	if text.text != ".1192.178.0" {
+		t.Errorf("pasting at the start gave %q", text.text)
+	}
+	press(glfw.KeyBackspace, 0)
+	press(glfw.KeyBackspace, 0)
+	if text.text != "192.178.0" {
+		t.Errorf("backspace gave %q", text.text)
+	}
+
+	press(glfw.KeyA, glfw.ModControl)
+	text.runeTyped('x')
+	if text.text != "x" {
+		t.Errorf("typing over everything gave %q", text.text)
+	}
+}
+
+func TestClickingInATextBoxMovesTheCaret(t *testing.T) {
+	g := testGraphics()
+	text := newTextBox(lang.Name, rect{0, 0, 600, 50}, g, nil)
+	text.text = "abcd"
+	m := newFocusManager(text)
+	m.draw(g)
+	x := text.runeX(2)
+	m.click(x+1, 25)
+	if start, end := text.selection(); start != 2 || end != 2 {
+		t.Errorf("click put the caret at %d-%d", start, end)
+	}
+	text.runeTyped('X')
+	if text.text != "abXcd" {
+		t.Errorf("typing after the click gave %q", text.text)
+	}
+}
+
+func TestTextBoxIgnoresInvalidEdits(t *testing.T) {
+	text := newTextBox(lang.Port, rect{0, 0, 600, 50}, testGraphics(), nil)
+	text.setValidator(validPortText)
+	text.setFocus(true)
+	for _, r := range "80a80" {
+		text.runeTyped(r)
+	}
+	if text.text != "8080" {
+		t.Errorf("port box accepted %q", text.text)
+	}
+}
+
+func TestValidators(t *testing.T) {
+	for _, test := range []struct {
+		valid func(string) bool
+		text  string
+		want  bool
+	}{
+		{validPortText, "", true},
+		{validPortText, "65535", true},
+		{validPortText, "65536", false},
+		{validPortText, "80 ", false},
+		{validHostText, "192.168.", true},
+		{validHostText, "192.168.0.255", true},
+		{validHostText, "192.168.0.256", false},
+		{validHostText, "1.2.3.4.5", false},
+		{validHostText, "my-host.local", true},
+		{validHostText, "-host", false},
+		{validHostText, "host name", false},
+		{validHostText, "::1", true},
+	} {
+		if got := test.valid(test.text); got != test.want {
+			t.Errorf("%q: want %v but got %v", test.text, test.want, got)
+		}
+	}
+}
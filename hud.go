@@ -0,0 +1,249 @@
+package main
+
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/gonutz/settlers/game"
+	"strconv"
+)
+
+// The HUD is what is shown around the board during the game. Every part of it
+// sits in its own window with an anchor layout. All windows are laid out in the
+// whole HUD area, which is as big as the game window, so the parts stay at the
+// window edges when its size changes.
+
+const (
+	instructionBarH      = 90
+	instructionBarBorder = 25
+	dieSize              = 100
+	// diceSpacing is the distance between the centers of the two dice
+	diceSpacing       = 100
+	resourceBarMargin = 20
+	resourceBarBorder = 15
+	// hudMargin is the distance of the HUD parts to the window edges and to
+	// each other
+	hudMargin = 10
+	// diceMargin is the distance of the dice to the left window edge and to
+	// the instruction bar
+	diceMargin = 60
+)
+
+var (
+	instructionBackColor = [4]float32{0.5, 0.5, 1, 0.8}
+	resourceBarBackColor = [4]float32{0.8, 0.6, 0.5, 0.8}
+)
+
+func newHUD(g *graphics, ui *gameUI) *hud {
+	h := &hud{
+		instruction: newInstructionBar(func() (string, game.Color) {
+			color := ui.game.GetCurrentPlayer().Color
+			if ui.game.State == game.NotStarted {
+				color = game.White
+			}
+			return ui.stateInstruction(), color
+		}),
+		dice:      newDiceView(func() [2]int { return ui.game.Dice }),
+		resources: newResourceBar(g, ui.shownResources),
+		buyMenu:   newBuyMenu(ui),
+		eventLog:  newEventLog(g, ui),
+		playerPanels: newPlayerPanelList(ui.playerPanels, func() int {
+			x, _ := ui.boardToHUD(gameW, 0)
+			return x
+		}),
+	}
+	// the buy menu sits on top of the event log, the dice and the player
+	// panels below the instruction bar
+	h.buyMenuWindow = newWindow(rect{},
+		newAnchorLayout(anchorLeft|anchorBottom, 0, hudMargin+h.eventLog.h+hudMargin), h.buyMenu)
+	h.eventLogWindow = newWindow(rect{},
+		newAnchorLayout(anchorLeft|anchorBottom, hudMargin, hudMargin), h.eventLog)
+	h.diceWindow = newWindow(rect{},
+		newAnchorLayout(anchorLeft|anchorTop, diceMargin, h.instruction.h+diceMargin), h.dice)
+	h.playerPanelsWindow = newWindow(rect{},
+		newAnchorLayout(anchorRight|anchorTop, hudMargin, h.instruction.h+hudMargin), h.playerPanels)
+	h.composite = newComposite(
+		h.buyMenuWindow,
+		newWindow(rect{}, newAnchorLayout(anchorBottom, 0, 2*hudMargin), h.resources),
+		h.eventLogWindow,
+		newWindow(rect{}, newAnchorLayout(anchorTop, 0, 0), h.instruction),
+		h.diceWindow,
+		h.playerPanelsWindow,
+	)
+	h.setBounds(ui.camera.hudBounds())
+	return h
+}
+
+type hud struct {
+	*composite
+	instruction        *instructionBar
+	dice               *diceView
+	resources          *resourceBar
+	buyMenu            *buyMenu
+	eventLog           *textLog
+	playerPanels       *playerPanelList
+	buyMenuWindow      *window
+	eventLogWindow     *window
+	diceWindow         *window
+	playerPanelsWindow *window
+}
+
+// setBounds anchors all parts of the HUD to the edges of the given area.
+func (h *hud) setBounds(area rect) {
+	for _, e := range h.elems {
+		e.setBounds(area)
+	}
+}
+
+// update shows the parts of the HUD that belong to the current state of the
+// game, moves the buy menu and collapses the player panels if they would cover
+// the board.
+func (h *hud) update(ui *gameUI) {
+	state := ui.game.State
+	h.buyMenuWindow.setVisible(state > game.BuildingSecondRoad && ui.isLocalTurn())
+	h.eventLogWindow.setVisible(state != game.NotStarted)
+	h.diceWindow.setVisible(state != game.RollingDice && state > game.BuildingSecondRoad &&
+		!ui.animations.playing(diceAnimation))
+	h.playerPanelsWindow.setVisible(state != game.NotStarted)
+	if h.buyMenuWindow.visible {
+		h.buyMenu.update()
+	}
+	if h.playerPanelsWindow.visible {
+		h.playerPanels.update()
+	}
+}
+
+// covers returns whether a visible part of the HUD is at x,y, the board under
+// it can not be pointed at there.
+func (h *hud) covers(x, y int) bool {
+	return h.instruction.contains(x, y) || h.resources.contains(x, y) ||
+		h.diceWindow.visible && h.dice.contains(x, y) ||
+		h.eventLogWindow.visible && h.eventLog.contains(x, y) ||
+		h.playerPanelsWindow.visible && h.playerPanels.contains(x, y) ||
+		h.buyMenuWindow.visible &&
+			(h.buyMenu.mainRect().contains(x, y) || h.buyMenu.iconRect().contains(x, y))
+}
+
+// hudElement implements the input methods of guiElement for the HUD parts
+// that only show information.
+type hudElement struct {
+	rect
+}
+
+func (e *hudElement) bounds() rect                              { return e.rect }
+func (e *hudElement) setBounds(bounds rect)                     { e.rect = bounds }
+func (e *hudElement) mouseMovedTo(x, y int)                     {}
+func (e *hudElement) click(x, y int) (actionID int)             { return -1 }
+func (e *hudElement) runeTyped(rune)                            {}
+func (e *hudElement) keyPressed(glfw.Key, glfw.ModifierKey) int { return -1 }
+
+// instruction bar
+
+// newInstructionBar creates the bar that tells the player what to do next, in
+// the color of the current player. It is as wide as the board, the background
+// is only drawn behind the text.
+func newInstructionBar(instruction func() (string, game.Color)) *instructionBar {
+	return &instructionBar{
+		hudElement:  hudElement{rect{0, 0, gameW, instructionBarH}},
+		instruction: instruction,
+	}
+}
+
+type instructionBar struct {
+	hudElement
+	instruction func() (string, game.Color)
+}
+
+func (b *instructionBar) draw(g *graphics) {
+	msg, color := b.instruction()
+	textWidth, textHeight := g.renderer.TextSize(msg)
+	w := textWidth + 2*instructionBarBorder
+	x := b.x + (b.w-w)/2
+	g.rect(x, b.y, w, b.h, instructionBackColor)
+	g.renderer.text(
+		msg,
+		float64(x+instructionBarBorder),
+		float64(b.y)+float64(b.h-textHeight)/2+g.renderer.fontSize(),
+		gameColorToFloats(color),
+	)
+}
+
+// dice
+
+func newDiceView(dice func() [2]int) *diceView {
+	return &diceView{
+		hudElement: hudElement{rect{0, 0, dieSize + diceSpacing, dieSize}},
+		dice:       dice,
+	}
+}
+
+type diceView struct {
+	hudElement
+	dice func() [2]int
+}
+
+// dieCenter returns the center of the first (0) or second (1) die.
+func (d *diceView) dieCenter(i int) (x, y int) {
+	return d.x + dieSize/2 + i*diceSpacing, d.y + dieSize/2
+}
+
+func (d *diceView) draw(g *graphics) {
+	for i, face := range d.dice() {
+		x, y := d.dieCenter(i)
+		g.drawImageCenteredAt(dieImage(face), x, y)
+	}
+}
+
+// resource bar
+
+// newResourceBar creates the bar that shows how many cards of each resource
+// the player has. Every resource gets a cell as big as the biggest symbol with
+// the count underneath it. shown returns the player whose cards are shown.
+func newResourceBar(g *graphics, shown func() game.Player) *resourceBar {
+	b := &resourceBar{shown: shown}
+	for r := 0; r < game.ResourceCount; r++ {
+		w, h := g.imageSize(resourceSymbol(game.Resource(r)))
+		if w > b.cellW {
+			b.cellW = w
+		}
+		if h > b.cellH {
+			b.cellH = h
+		}
+	}
+	b.w = game.ResourceCount*b.cellW + (game.ResourceCount-1)*resourceBarMargin +
+		2*resourceBarBorder
+	// the counts are written underneath the symbols
+	b.h = b.cellH + int(g.renderer.fontSize()) + 2*resourceBarBorder
+	return b
+}
+
+type resourceBar struct {
+	hudElement
+	cellW, cellH int
+	shown        func() game.Player
+}
+
+// symbolCenter returns the center of the resource's symbol.
+func (b *resourceBar) symbolCenter(r game.Resource) (x, y int) {
+	x = b.x + resourceBarBorder + int(r)*(b.cellW+resourceBarMargin) + b.cellW/2
+	return x, b.y + resourceBarBorder + b.cellH/2
+}
+
+func (b *resourceBar) draw(g *graphics) {
+	g.rect(b.x, b.y, b.w, b.h, resourceBarBackColor)
+	p := b.shown()
+	color := playerColor(p.Color)
+	x, y := b.x+resourceBarBorder, b.y+resourceBarBorder
+	textY := float64(y+b.cellH) + g.renderer.fontSize()
+	for r := 0; r < game.ResourceCount; r++ {
+		id := resourceSymbol(game.Resource(r))
+		w, _ := g.imageSize(id)
+		g.renderer.image(id, x+(b.cellW-w)/2, y)
+		text := strconv.Itoa(p.Resources[r])
+		textW, _ := g.renderer.TextSize(text)
+		g.renderer.text(text, float64(x+(b.cellW-textW)/2), textY, color)
+		x += b.cellW + resourceBarMargin
+	}
+}
+
+func resourceSymbol(r game.Resource) string {
+	return resourceToString(r) + "_symbol"
+}
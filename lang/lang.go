@@ -48,6 +48,71 @@ const (
 	BuildCity
 	ChooseNextAction
 	RollDice
+	Connecting
+	WaitingForHost
+	NotConnected
+	WaitingForPlayersOnPort
+	FreeSeat
+	WaitForOtherPlayers
+	Reconnecting
+	WaitingForReconnect
+	AIForDisconnected
+	FreeSeats
+	OtherVersion
+	VictoryPointsShort
+	Knights
+	LongestRoad
+	LargestArmy
+	Lumber
+	Brick
+	Wool
+	Ore
+	Grain
+	DiceRolledEvent
+	ResourcesReceivedEvent
+	RoadBuiltEvent
+	SettlementBuiltEvent
+	CityBuiltEvent
+	DevelopmentCardBoughtEvent
+	Statistics
+	DiceRolls
+	Rolled
+	Expected
+	Income
+	TileRolls
+	Yes
+	No
+	Cancel
+	QuitQuestion
+	ConnectionLost
+	Road
+	Settlement
+	City
+	DevelopmentCard
+	RoadEffect
+	SettlementEffect
+	CityEffect
+	DevelopmentCardEffect
+	CostWord
+	TwoToOneHarborTooltip
+	ThreeToOneHarborTooltip
+	NumberTooltip
+	SettlementOwnerTooltip
+	CityOwnerTooltip
+	DisplayWord
+	DefaultTheme
+	HighContrastTheme
+	NormalSize
+	LargeSize
+	VeryLargeSize
+	ClassicColors
+	OkabeItoColors
+	TolColors
+	PlayerMarkers
+	ActionRejected
+	ExportBoard
+	BoardExported
+	LastItem // NOTE this has to always come last
 )
 
 var languages = [][]string{
@@ -82,6 +147,70 @@ var languages = [][]string{
 		"Build your City",
 		"Choose your next Action",
 		"Roll the Dice",
+		"Connecting...",
+		"Waiting for the host to start",
+		"Not connected",
+		"Waiting for players on port",
+		"free seat",
+		"Wait for the other players",
+		"Connection lost, reconnecting...",
+		"Waiting for a player to reconnect",
+		"Computer plays for disconnected players",
+		"free seats",
+		"other version",
+		"VP",
+		"Knights:",
+		"Longest Road",
+		"Largest Army",
+		"lumber",
+		"brick",
+		"wool",
+		"ore",
+		"grain",
+		"%s rolled %d",
+		"%s received %d %s",
+		"%s built a road",
+		"%s built a settlement",
+		"%s built a city",
+		"%s bought a development card",
+		"Statistics",
+		"Dice rolls",
+		"rolled",
+		"expected",
+		"Income",
+		"Tiles: produced / blocked by the robber",
+		"Yes",
+		"No",
+		"Cancel",
+		"Quit the game?",
+		"Connection lost",
+		"Road",
+		"Settlement",
+		"City",
+		"Development Card",
+		"Connects your buildings, the longest road is worth 2 VP",
+		"Produces resources from its tiles, worth 1 VP",
+		"Replaces a settlement, produces twice as much, worth 2 VP",
+		"A knight, a progress card or a victory point",
+		"Cost:",
+		"2:1 harbor: trade 2 %s for any one resource",
+		"3:1 harbor: trade 3 equal resources for any one",
+		"%d comes up in %d of 36 rolls (%d%%)",
+		"Settlement of %s",
+		"City of %s",
+		"Display",
+		"Normal colors",
+		"High contrast",
+		"Normal size",
+		"Large",
+		"Very large",
+		"Classic colors",
+		"Okabe-Ito colors",
+		"Tol colors",
+		"Player symbols",
+		"Action rejected",
+		"Export Board",
+		"The board was saved as %s.png and %s.svg",
 	},
 
 	// German
@@ -115,5 +244,69 @@ var languages = [][]string{
 		"Baue deine Stadt",
 		"Wähle deine nächste Aktion",
 		"Würfle",
+		"Verbinde...",
+		"Warte auf den Start des Spiels",
+		"Nicht verbunden",
+		"Warte auf Spieler an Port",
+		"freier Platz",
+		"Warte auf die anderen Spieler",
+		"Verbindung verloren, verbinde neu...",
+		"Warte, bis ein Spieler sich neu verbindet",
+		"Computer spielt für getrennte Spieler",
+		"freie Plätze",
+		"andere Version",
+		"SP",
+		"Ritter:",
+		"Längste Straße",
+		"Größte Rittermacht",
+		"Holz",
+		"Lehm",
+		"Wolle",
+		"Erz",
+		"Getreide",
+		"%s würfelt %d",
+		"%s erhält %d %s",
+		"%s baut eine Straße",
+		"%s baut eine Siedlung",
+		"%s baut eine Stadt",
+		"%s kauft eine Entwicklungskarte",
+		"Statistik",
+		"Würfe",
+		"gewürfelt",
+		"erwartet",
+		"Einnahmen",
+		"Felder: Erträge / vom Räuber blockiert",
+		"Ja",
+		"Nein",
+		"Abbrechen",
+		"Spiel beenden?",
+		"Verbindung verloren",
+		"Straße",
+		"Siedlung",
+		"Stadt",
+		"Entwicklungskarte",
+		"Verbindet deine Gebäude, die längste Straße zählt 2 SP",
+		"Erhält Rohstoffe von ihren Feldern, zählt 1 SP",
+		"Ersetzt eine Siedlung, erhält doppelt so viel, zählt 2 SP",
+		"Ein Ritter, eine Fortschrittskarte oder ein Siegpunkt",
+		"Kosten:",
+		"2:1 Hafen: tausche 2 %s gegen einen beliebigen Rohstoff",
+		"3:1 Hafen: tausche 3 gleiche Rohstoffe gegen einen beliebigen",
+		"Die %d fällt bei %d von 36 Würfen (%d%%)",
+		"Siedlung von %s",
+		"Stadt von %s",
+		"Anzeige",
+		"Normale Farben",
+		"Hoher Kontrast",
+		"Normale Größe",
+		"Groß",
+		"Sehr groß",
+		"Klassische Farben",
+		"Okabe-Ito-Farben",
+		"Tol-Farben",
+		"Spielersymbole",
+		"Aktion abgelehnt",
+		"Spielbrett exportieren",
+		"Das Spielbrett wurde als %s.png und %s.svg gespeichert",
 	},
 }
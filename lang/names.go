@@ -0,0 +1,105 @@
+package lang
+
+// ItemByName returns the item that has the given name in this package, e.g.
+// "NewGame" for NewGame. Data files like the menu descriptions refer to texts
+// by these names.
+func ItemByName(name string) (Item, bool) {
+	item, ok := itemNames[name]
+	return item, ok
+}
+
+var itemNames = map[string]Item{
+	"EnglishName":                EnglishName,
+	"GermanName":                 GermanName,
+	"Title":                      Title,
+	"Menu":                       Menu,
+	"NewGame":                    NewGame,
+	"JoinRemoteGame":             JoinRemoteGame,
+	"Quit":                       Quit,
+	"LanguageWord":               LanguageWord,
+	"ThreePlayers":               ThreePlayers,
+	"FourPlayers":                FourPlayers,
+	"Back":                       Back,
+	"StartGame":                  StartGame,
+	"OK":                         OK,
+	"PlayHere":                   PlayHere,
+	"AIPlayer":                   AIPlayer,
+	"NetworkPlayer":              NetworkPlayer,
+	"Name":                       Name,
+	"IP":                         IP,
+	"Port":                       Port,
+	"Connect":                    Connect,
+	"BuildFirstSettlement":       BuildFirstSettlement,
+	"BuildSecondSettlement":      BuildSecondSettlement,
+	"BuildFirstRoad":             BuildFirstRoad,
+	"BuildSecondRoad":            BuildSecondRoad,
+	"BuildRoad":                  BuildRoad,
+	"BuildSettlement":            BuildSettlement,
+	"BuildCity":                  BuildCity,
+	"ChooseNextAction":           ChooseNextAction,
+	"RollDice":                   RollDice,
+	"Connecting":                 Connecting,
+	"WaitingForHost":             WaitingForHost,
+	"NotConnected":               NotConnected,
+	"WaitingForPlayersOnPort":    WaitingForPlayersOnPort,
+	"FreeSeat":                   FreeSeat,
+	"WaitForOtherPlayers":        WaitForOtherPlayers,
+	"Reconnecting":               Reconnecting,
+	"WaitingForReconnect":        WaitingForReconnect,
+	"AIForDisconnected":          AIForDisconnected,
+	"FreeSeats":                  FreeSeats,
+	"OtherVersion":               OtherVersion,
+	"VictoryPointsShort":         VictoryPointsShort,
+	"Knights":                    Knights,
+	"LongestRoad":                LongestRoad,
+	"LargestArmy":                LargestArmy,
+	"Lumber":                     Lumber,
+	"Brick":                      Brick,
+	"Wool":                       Wool,
+	"Ore":                        Ore,
+	"Grain":                      Grain,
+	"DiceRolledEvent":            DiceRolledEvent,
+	"ResourcesReceivedEvent":     ResourcesReceivedEvent,
+	"RoadBuiltEvent":             RoadBuiltEvent,
+	"SettlementBuiltEvent":       SettlementBuiltEvent,
+	"CityBuiltEvent":             CityBuiltEvent,
+	"DevelopmentCardBoughtEvent": DevelopmentCardBoughtEvent,
+	"Statistics":                 Statistics,
+	"DiceRolls":                  DiceRolls,
+	"Rolled":                     Rolled,
+	"Expected":                   Expected,
+	"Income":                     Income,
+	"TileRolls":                  TileRolls,
+	"Yes":                        Yes,
+	"No":                         No,
+	"Cancel":                     Cancel,
+	"QuitQuestion":               QuitQuestion,
+	"ConnectionLost":             ConnectionLost,
+	"Road":                       Road,
+	"Settlement":                 Settlement,
+	"City":                       City,
+	"DevelopmentCard":            DevelopmentCard,
+	"RoadEffect":                 RoadEffect,
+	"SettlementEffect":           SettlementEffect,
+	"CityEffect":                 CityEffect,
+	"DevelopmentCardEffect":      DevelopmentCardEffect,
+	"CostWord":                   CostWord,
+	"TwoToOneHarborTooltip":      TwoToOneHarborTooltip,
+	"ThreeToOneHarborTooltip":    ThreeToOneHarborTooltip,
+	"NumberTooltip":              NumberTooltip,
+	"SettlementOwnerTooltip":     SettlementOwnerTooltip,
+	"DisplayWord":                DisplayWord,
+	"DefaultTheme":               DefaultTheme,
+	"HighContrastTheme":          HighContrastTheme,
+	"NormalSize":                 NormalSize,
+	"LargeSize":                  LargeSize,
+	"VeryLargeSize":              VeryLargeSize,
+	"ClassicColors":              ClassicColors,
+	"OkabeItoColors":             OkabeItoColors,
+	"TolColors":                  TolColors,
+	"PlayerMarkers":              PlayerMarkers,
+	"ActionRejected":             ActionRejected,
+	"ExportBoard":                ExportBoard,
+	"BoardExported":              BoardExported,
+	"CityOwnerTooltip":           CityOwnerTooltip,
+}
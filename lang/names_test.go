@@ -0,0 +1,23 @@
+package lang
+
+import "testing"
+
+func TestEveryItemHasANameAndTexts(t *testing.T) {
+	named := make(map[Item]bool)
+	for name, item := range itemNames {
+		if named[item] {
+			t.Errorf("item %d has more than one name, one is %q", item, name)
+		}
+		named[item] = true
+	}
+	for item := Item(0); item < LastItem; item++ {
+		if !named[item] {
+			t.Errorf("item %d has no name", item)
+		}
+	}
+	for language, texts := range languages {
+		if len(texts) != int(LastItem) {
+			t.Errorf("language %d has %d texts for %d items", language, len(texts), LastItem)
+		}
+	}
+}
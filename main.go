@@ -1,9 +1,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/go-gl/glfw/v3.1/glfw"
+	"os"
 	"runtime"
 	"time"
 )
@@ -13,6 +15,18 @@ func init() {
 }
 
 func main() {
+	exportPath := flag.String("export", "",
+		"write the board of a new game to this .png or .svg file and quit")
+	seed := flag.Int("seed", 0, "random seed for the board written with -export")
+	flag.Parse()
+	if *exportPath != "" {
+		if err := exportNewBoard(*exportPath, *seed); err != nil {
+			fmt.Println("cannot export board:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := glfw.Init(); err != nil {
 		fmt.Println("glfw.Init():", err)
 		return
@@ -40,6 +54,7 @@ func main() {
 	window.SetMouseButtonCallback(mouseButtonCallback)
 	window.SetCursorEnterCallback(cursorEnterCallback)
 	window.SetCursorPosCallback(cursorPositionCallback)
+	window.SetScrollCallback(scrollCallback)
 	window.SetCharCallback(charCallback)
 	window.SetSizeCallback(sizeCallback)
 
@@ -74,9 +89,9 @@ func (w uiWindow) Close() {
 
 var ui *gameUI
 
-func keyCallback(_ *glfw.Window, key glfw.Key, _ int, action glfw.Action, _ glfw.ModifierKey) {
+func keyCallback(_ *glfw.Window, key glfw.Key, _ int, action glfw.Action, mods glfw.ModifierKey) {
 	if action == glfw.Press || action == glfw.Repeat {
-		ui.KeyDown(key)
+		ui.KeyDown(key, mods)
 	}
 }
 
@@ -84,6 +99,13 @@ func mouseButtonCallback(_ *glfw.Window, button glfw.MouseButton, action glfw.Ac
 	if action == glfw.Press {
 		ui.MouseButtonDown(button)
 	}
+	if action == glfw.Release {
+		ui.MouseButtonUp(button)
+	}
+}
+
+func scrollCallback(_ *glfw.Window, _, yoff float64) {
+	ui.MouseWheel(yoff)
 }
 
 func cursorEnterCallback(_ *glfw.Window, entered bool) {
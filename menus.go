@@ -0,0 +1,502 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/lang"
+	"github.com/gonutz/settlers/settings"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The menus are described in menus.json, a list of windows that contain the
+// widgets. Widgets name their texts by their lang.Item names and name the
+// handlers, values and validators that the program registers in a menuBuilder.
+// This way the menus can change without touching the code that handles them.
+
+// menuSpec is a window at the top level of the menu description, only one of
+// them is visible at a time.
+type menuSpec struct {
+	Name    string
+	Visible bool
+	Layout  layoutSpec
+	// Elements are the widgets in the window
+	Elements []elementSpec
+}
+
+type layoutSpec struct {
+	// Type is verticalFlow, horizontalFlow, grid, center or topLeft
+	Type string
+	// Space is the margin between elements in flow and grid layouts
+	Space   int
+	Columns int
+}
+
+type elementSpec struct {
+	// Type is button, checkBox, checkBoxGroup, textBox, label, spacer, window,
+	// playerTabs or custom
+	Type string
+	// ID names the element so the code and other elements in the same window
+	// can find it
+	ID string
+	// Text is the name of the lang.Item that the element shows
+	Text string
+	// W and H are the element's size, buttons, check boxes and text boxes
+	// that leave them out get the theme's button size
+	W, H int
+	// Action is the name of the handler that is called when the element is
+	// activated, after that the menu named by Opens is shown
+	Action string
+	Opens  string
+	// Bind is the name of the value that the element shows and changes. In
+	// the windows of playerTabs, a # in it stands for the tab's index.
+	Bind     string
+	Validate string
+	// EnabledBy is the ID of a check box in the same window, the element is
+	// only enabled while it is checked
+	EnabledBy string
+	// Layout and Elements are the contents of windows, check box groups and
+	// player tabs
+	Layout   *layoutSpec
+	Elements []elementSpec
+	CaptionH int
+	// Name is the name of the builder for custom elements
+	Name string
+}
+
+// textValue connects a text box to a value in the program, e.g. a setting.
+type textValue struct {
+	get func() string
+	set func(string)
+}
+
+// checkValue connects a check box to a value in the program. set is only
+// called when the user changes the check box.
+type checkValue struct {
+	get func() bool
+	set func(bool)
+}
+
+// menuBuilder creates the windows from a menu description. Register the names
+// that the description uses in its maps before loading it.
+type menuBuilder struct {
+	font       textSizer
+	clip       clipboard
+	actions    map[string]func()
+	texts      map[string]textValue
+	checks     map[string]checkValue
+	labels     map[string]func() string
+	validators map[string]func(string) bool
+	// custom builders create elements that are too special to describe, they
+	// can use the elements that were built before them
+	custom map[string]func(b *menuBuilder) ([]guiElement, error)
+	// showMenu is called for elements that open another menu
+	showMenu func(name string)
+
+	// handlers are the actions of the built elements, the elements' action
+	// IDs are indices into it
+	handlers []func()
+	// ids are the built elements with an ID, for player tabs only the ones in
+	// the last tab are kept
+	ids map[string]guiElement
+	// tabIndex replaces the # in bind names while building player tabs
+	tabIndex int
+	// used are the names that the built elements looked up, by their kind,
+	// e.g. "action quit"
+	used map[string]bool
+}
+
+func newMenuBuilder(font textSizer, clip clipboard) *menuBuilder {
+	return &menuBuilder{
+		font:       font,
+		clip:       clip,
+		actions:    make(map[string]func()),
+		texts:      make(map[string]textValue),
+		checks:     make(map[string]checkValue),
+		labels:     make(map[string]func() string),
+		validators: make(map[string]func(string) bool),
+		custom:     make(map[string]func(b *menuBuilder) ([]guiElement, error)),
+		ids:        make(map[string]guiElement),
+		used:       make(map[string]bool),
+	}
+}
+
+// unusedNames returns the actions, values, validators and custom elements that
+// no element used, by their kind as in used.
+func (b *menuBuilder) unusedNames() []string {
+	var names []string
+	add := func(kind, name string) {
+		if !b.used[kind+" "+name] {
+			names = append(names, kind+" "+name)
+		}
+	}
+	for name := range b.actions {
+		add("action", name)
+	}
+	for name := range b.texts {
+		add("text", name)
+	}
+	for name := range b.checks {
+		add("check", name)
+	}
+	for name := range b.labels {
+		add("label", name)
+	}
+	for name := range b.validators {
+		add("validator", name)
+	}
+	for name := range b.custom {
+		add("custom", name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadMenus builds the menus described in the given file. They are returned
+// in the order of the file and by their names.
+func (b *menuBuilder) loadMenus(path string) ([]guiElement, map[string]*window, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	return b.readMenus(file)
+}
+
+func (b *menuBuilder) readMenus(r io.Reader) ([]guiElement, map[string]*window, error) {
+	var specs []menuSpec
+	if err := json.NewDecoder(r).Decode(&specs); err != nil {
+		return nil, nil, errors.New("menu description: " + err.Error())
+	}
+	var menus []guiElement
+	byName := make(map[string]*window)
+	for _, spec := range specs {
+		w, err := b.window(rect{0, 0, gameW, gameH}, spec.Layout, spec.Elements)
+		if err != nil {
+			return nil, nil, errors.New("menu " + spec.Name + ": " + err.Error())
+		}
+		w.setVisible(spec.Visible)
+		menus = append(menus, w)
+		byName[spec.Name] = w
+	}
+	for _, spec := range specs {
+		for _, e := range spec.Elements {
+			if err := b.checkOpens(e, byName); err != nil {
+				return nil, nil, errors.New("menu " + spec.Name + ": " + err.Error())
+			}
+		}
+	}
+	return menus, byName, nil
+}
+
+// checkOpens makes sure that all menus that the elements open exist.
+func (b *menuBuilder) checkOpens(e elementSpec, menus map[string]*window) error {
+	if _, ok := menus[e.Opens]; e.Opens != "" && !ok {
+		return errors.New("unknown menu " + e.Opens)
+	}
+	for _, child := range e.Elements {
+		if err := b.checkOpens(child, menus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// window builds a window with the given elements. IDs in EnabledBy refer to
+// check boxes in this window.
+func (b *menuBuilder) window(bounds rect, l layoutSpec, specs []elementSpec) (*window, error) {
+	layout, err := newLayoutFromSpec(l)
+	if err != nil {
+		return nil, err
+	}
+	checkBoxes := make(map[string]*checkBox)
+	var elems []guiElement
+	enabledBy := make(map[guiElement]string)
+	for _, spec := range specs {
+		built, err := b.element(spec, checkBoxes)
+		if err != nil {
+			return nil, err
+		}
+		if spec.EnabledBy != "" && len(built) == 1 {
+			enabledBy[built[0]] = spec.EnabledBy
+		}
+		elems = append(elems, built...)
+	}
+	// the check boxes may come after the elements that they enable
+	for e, id := range enabledBy {
+		if err := enableBy(e, id, checkBoxes); err != nil {
+			return nil, err
+		}
+	}
+	return newWindow(bounds, layout, elems...), nil
+}
+
+func newLayoutFromSpec(spec layoutSpec) (layout, error) {
+	switch spec.Type {
+	case "verticalFlow":
+		return newVerticalFlowLayout(spec.Space), nil
+	case "horizontalFlow":
+		return newHorizontalFlowLayout(spec.Space), nil
+	case "grid":
+		if spec.Columns <= 0 {
+			return nil, errors.New("grid layout needs columns")
+		}
+		return newGridLayout(spec.Columns, spec.Space), nil
+	case "center":
+		return newCenterLayout(), nil
+	case "topLeft":
+		return newTopLeftLayout(), nil
+	}
+	return nil, errors.New("unknown layout type " + spec.Type)
+}
+
+// element builds the elements for the spec, most specs give exactly one.
+// Built check boxes with an ID are put into checkBoxes.
+func (b *menuBuilder) element(spec elementSpec, checkBoxes map[string]*checkBox) ([]guiElement, error) {
+	size := rect{0, 0, spec.W, spec.H}
+	var e guiElement
+	switch spec.Type {
+	case "button":
+		text, err := b.text(spec)
+		if err != nil {
+			return nil, err
+		}
+		action, err := b.action(spec)
+		if err != nil {
+			return nil, err
+		}
+		e = newButton(text, buttonSize(spec), action)
+	case "checkBox":
+		cb, err := b.checkBox(spec)
+		if err != nil {
+			return nil, err
+		}
+		if spec.ID != "" {
+			checkBoxes[spec.ID] = cb
+		}
+		e = cb
+	case "checkBoxGroup":
+		var boxes []*checkBox
+		for _, boxSpec := range spec.Elements {
+			if boxSpec.Type != "checkBox" {
+				return nil, errors.New("check box group contains a " + boxSpec.Type)
+			}
+			cb, err := b.checkBox(boxSpec)
+			if err != nil {
+				return nil, err
+			}
+			if boxSpec.ID != "" {
+				checkBoxes[boxSpec.ID] = cb
+			}
+			boxes = append(boxes, cb)
+		}
+		e = newCheckBoxGroup(boxes...)
+	case "textBox":
+		t, err := b.textBox(spec)
+		if err != nil {
+			return nil, err
+		}
+		e = t
+	case "label":
+		text, ok := b.labels[b.bindName(spec.Bind)]
+		b.used["label "+b.bindName(spec.Bind)] = true
+		if !ok {
+			return nil, errors.New("unknown label value " + spec.Bind)
+		}
+		e = newLabel(size, text)
+	case "spacer":
+		e = newSpacer(size)
+	case "window":
+		if spec.Layout == nil {
+			return nil, errors.New("window without layout")
+		}
+		w, err := b.window(rect{}, *spec.Layout, spec.Elements)
+		if err != nil {
+			return nil, err
+		}
+		e = w
+	case "playerTabs":
+		sheet, err := b.playerTabs(spec)
+		if err != nil {
+			return nil, err
+		}
+		e = sheet
+	case "custom":
+		build, ok := b.custom[spec.Name]
+		b.used["custom "+spec.Name] = true
+		if !ok {
+			return nil, errors.New("unknown custom element " + spec.Name)
+		}
+		return build(b)
+	default:
+		return nil, errors.New("unknown element type " + spec.Type)
+	}
+	if spec.ID != "" {
+		b.ids[spec.ID] = e
+	}
+	return []guiElement{e}, nil
+}
+
+// buttonSize returns the size of a button, check box or text box. The theme's
+// button size is used for what the spec leaves out.
+func buttonSize(spec elementSpec) rect {
+	size := rect{0, 0, spec.W, spec.H}
+	if size.w == 0 {
+		size.w = buttonW
+	}
+	if size.h == 0 {
+		size.h = buttonH
+	}
+	return size
+}
+
+func (b *menuBuilder) text(spec elementSpec) (lang.Item, error) {
+	item, ok := lang.ItemByName(spec.Text)
+	if !ok {
+		return 0, errors.New("unknown text " + spec.Text)
+	}
+	return item, nil
+}
+
+// action returns the action ID for the element's handler and the menu that it
+// opens, it is -1 if there is neither.
+func (b *menuBuilder) action(spec elementSpec) (int, error) {
+	if spec.Action == "" && spec.Opens == "" {
+		return -1, nil
+	}
+	action, ok := b.actions[spec.Action]
+	b.used["action "+spec.Action] = true
+	if spec.Action != "" && !ok {
+		return -1, errors.New("unknown action " + spec.Action)
+	}
+	opens := spec.Opens
+	b.handlers = append(b.handlers, func() {
+		if action != nil {
+			action()
+		}
+		if opens != "" && b.showMenu != nil {
+			b.showMenu(opens)
+		}
+	})
+	return len(b.handlers) - 1, nil
+}
+
+// bindName replaces the # in the name with the index of the player tab that
+// is being built.
+func (b *menuBuilder) bindName(name string) string {
+	return strings.Replace(name, "#", strconv.Itoa(b.tabIndex), -1)
+}
+
+func (b *menuBuilder) checkBox(spec elementSpec) (*checkBox, error) {
+	text, err := b.text(spec)
+	if err != nil {
+		return nil, err
+	}
+	action, err := b.action(spec)
+	if err != nil {
+		return nil, err
+	}
+	cb := newCheckBox(text, buttonSize(spec), action)
+	if spec.Bind != "" {
+		value, ok := b.checks[b.bindName(spec.Bind)]
+		b.used["check "+b.bindName(spec.Bind)] = true
+		if !ok {
+			return nil, errors.New("unknown check box value " + spec.Bind)
+		}
+		cb.checked = value.get()
+		cb.checkChangeEvent = value.set
+	}
+	if spec.ID != "" {
+		b.ids[spec.ID] = cb
+	}
+	return cb, nil
+}
+
+func (b *menuBuilder) textBox(spec elementSpec) (*textBox, error) {
+	caption, err := b.text(spec)
+	if err != nil {
+		return nil, err
+	}
+	t := newTextBox(caption, buttonSize(spec), b.font, b.clip)
+	if spec.Validate != "" {
+		valid, ok := b.validators[spec.Validate]
+		b.used["validator "+spec.Validate] = true
+		if !ok {
+			return nil, errors.New("unknown validator " + spec.Validate)
+		}
+		t.setValidator(valid)
+	}
+	if spec.Bind != "" {
+		value, ok := b.texts[b.bindName(spec.Bind)]
+		b.used["text "+b.bindName(spec.Bind)] = true
+		if !ok {
+			return nil, errors.New("unknown text value " + spec.Bind)
+		}
+		t.text = value.get()
+		t.onTextChange(value.set)
+	}
+	return t, nil
+}
+
+// playerTabs builds a tab sheet with one tab in each player's color. Every tab
+// shows its own copy of the window that is the only element of the spec. The
+// tabs of players beyond the player count are hidden.
+func (b *menuBuilder) playerTabs(spec elementSpec) (*tabSheet, error) {
+	if len(spec.Elements) != 1 || spec.Elements[0].Type != "window" {
+		return nil, errors.New("player tabs need exactly one window")
+	}
+	var tabs []*tab
+	for i := 0; i < 4; i++ {
+		b.tabIndex = i
+		content, err := b.element(spec.Elements[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		visible := i < settings.Settings.PlayerCount
+		tabs = append(tabs, newPlayerTab(game.Color(i), content[0], visible))
+	}
+	b.tabIndex = 0
+	return newTabSheet(spec.CaptionH, tabs...), nil
+}
+
+// newPlayerTab shows the color of the player in the current palette and the
+// player's marker.
+func newPlayerTab(color game.Color, content guiElement, visible bool) *tab {
+	t := newTab(fullPlayerColor(color), content, visible)
+	t.onColor(func() [4]float32 { return fullPlayerColor(color) })
+	t.onDecorate(func(g *graphics, caption rect) {
+		g.drawPlayerMarker(caption.x+caption.w/2, caption.y+caption.h/2, color, 5)
+	})
+	return t
+}
+
+// enabler elements can be disabled.
+type enabler interface {
+	setEnabled(bool)
+}
+
+// enableBy makes the element follow the check box with the given ID, it is
+// only enabled while the box is checked.
+func enableBy(elem guiElement, id string, checkBoxes map[string]*checkBox) error {
+	cb, ok := checkBoxes[id]
+	if !ok {
+		return errors.New("unknown check box " + id)
+	}
+	e, ok := elem.(enabler)
+	if !ok {
+		return errors.New("element enabled by " + id + " can not be disabled")
+	}
+	e.setEnabled(cb.checked)
+	changed := cb.checkChangeEvent
+	cb.checkChangeEvent = func(checked bool) {
+		if changed != nil {
+			changed(checked)
+		}
+		e.setEnabled(checked)
+	}
+	return nil
+}
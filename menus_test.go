@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func testMenuBuilder() *menuBuilder {
+	return newMenuBuilder(testGraphics(), &fakeClipboard{})
+}
+
+func TestMenuButtonsRunTheirActionAndOpenTheirMenu(t *testing.T) {
+	b := testMenuBuilder()
+	quit := false
+	b.actions["quit"] = func() { quit = true }
+	var opened string
+	b.showMenu = func(name string) { opened = name }
+	menus, byName, err := b.readMenus(strings.NewReader(`[
+		{"name": "main", "visible": true, "layout": {"type": "verticalFlow"}, "elements": [
+			{"type": "button", "id": "quit", "text": "Quit", "w": 100, "h": 50, "action": "quit", "opens": "other"}
+		]},
+		{"name": "other", "layout": {"type": "center"}, "elements": []}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(menus) != 2 || !byName["main"].visible || byName["other"].visible {
+		t.Fatal("menus were not built as described")
+	}
+	b.handlers[b.ids["quit"].(*button).action]()
+	if !quit || opened != "other" {
+		t.Errorf("button ran its action: %v, opened %q", quit, opened)
+	}
+}
+
+func TestMenuElementsAreEnabledByTheirCheckBox(t *testing.T) {
+	b := testMenuBuilder()
+	b.texts["ip"] = textValue{get: func() string { return "" }, set: func(string) {}}
+	_, _, err := b.readMenus(strings.NewReader(`[
+		{"name": "main", "layout": {"type": "verticalFlow"}, "elements": [
+			{"type": "textBox", "id": "ip", "text": "IP", "w": 100, "h": 50, "bind": "ip", "enabledBy": "network"},
+			{"type": "checkBox", "id": "network", "text": "NetworkPlayer", "w": 100, "h": 50}
+		]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ip, network := b.ids["ip"].(*textBox), b.ids["network"].(*checkBox)
+	if !ip.disabled {
+		t.Error("text box is enabled while its check box is not checked")
+	}
+	network.setChecked(true)
+	if ip.disabled {
+		t.Error("checking the box did not enable the text box")
+	}
+}
+
+func TestUnknownNamesInMenusAreErrors(t *testing.T) {
+	for _, element := range []string{
+		`{"type": "slider"}`,
+		`{"type": "button", "text": "NoSuchText"}`,
+		`{"type": "button", "text": "Quit", "action": "noSuchAction"}`,
+		`{"type": "button", "text": "Quit", "opens": "noSuchMenu"}`,
+		`{"type": "checkBox", "text": "Quit", "bind": "noSuchValue"}`,
+		`{"type": "textBox", "text": "IP", "validate": "noSuchValidator"}`,
+		`{"type": "textBox", "text": "IP", "enabledBy": "noSuchCheckBox"}`,
+		`{"type": "custom", "name": "noSuchElement"}`,
+	} {
+		menu := `[{"name": "main", "layout": {"type": "verticalFlow"}, "elements": [` +
+			element + `]}]`
+		if _, _, err := testMenuBuilder().readMenus(strings.NewReader(menu)); err == nil {
+			t.Errorf("no error for %s", element)
+		}
+	}
+}
+
+type testWindow struct{ fakeClipboard }
+
+func (*testWindow) Close()          {}
+func (*testWindow) SetTitle(string) {}
+
+func TestShippedMenusUseExactlyTheBoundNames(t *testing.T) {
+	ui := &gameUI{game: goldenGame(), camera: newCamera(), window: &testWindow{}}
+	if _, err := ui.loadMenus(testGraphics(), &fakeClipboard{}); err != nil {
+		t.Fatal(err)
+	}
+	b := ui.menuBuilder(testGraphics(), &fakeClipboard{})
+	if _, _, err := b.loadMenus(resourcePath("menus.json")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range b.unusedNames() {
+		t.Errorf("%s is bound but menus.json does not use it", name)
+	}
+}
@@ -0,0 +1,234 @@
+package network
+
+import (
+	"errors"
+	"github.com/gonutz/settlers/game"
+	"net"
+	"sync"
+	"time"
+)
+
+// Client is a network player's connection to a Server. It keeps the latest
+// view of the game that the server sent and can be used from the UI while the
+// messages are received in the background.
+// If the connection is lost during the game, the client keeps trying to get
+// back to its seat until it is closed.
+type Client struct {
+	mu     sync.Mutex
+	conn   *connection
+	status Status
+	err    error
+	seat   game.Color
+	token  string
+	seats  []SeatInfo
+	view   *GameView
+	closed bool
+	// welcomed is true once the server gave the current connection a seat
+	welcomed bool
+}
+
+type Status int
+
+const (
+	// Connecting means the client is trying to reach the server.
+	Connecting Status = iota
+	// InLobby means the client has a seat and waits for the host to start.
+	InLobby
+	// Playing means the game has started.
+	Playing
+	// Reconnecting means the connection was lost during the game and the
+	// client is trying to get back to its seat. The game stays as it was.
+	Reconnecting
+	// Disconnected means the connection failed or was lost, Err says why.
+	Disconnected
+)
+
+const (
+	dialTimeout    = 10 * time.Second
+	reconnectDelay = time.Second
+)
+
+var errConnectionLost = errors.New("the connection to the server was lost")
+
+// Join connects to the server at the given address, e.g. "192.168.0.2:5555",
+// and asks for a seat for a player with the given name. The token is the
+// session token of an earlier connection to this server, see Token. With it
+// the player gets back its old seat, if the server still knows it, otherwise
+// the token is ignored. It may be empty.
+// Join returns right away, the connection is made in the background. Use Status
+// to see how far it got.
+func Join(address, name, token string) *Client {
+	c := &Client{status: Connecting, token: token}
+	go c.run(address, name)
+	return c
+}
+
+func (c *Client) run(address, name string) {
+	for {
+		rejected, err := c.connect(address, name)
+		c.mu.Lock()
+		reconnect := !c.closed && !rejected &&
+			(c.status == Playing || c.status == Reconnecting)
+		if reconnect {
+			c.status = Reconnecting
+			c.conn = nil
+			c.err = nil
+		}
+		c.mu.Unlock()
+		if !reconnect {
+			c.disconnect(err)
+			return
+		}
+		time.Sleep(reconnectDelay)
+	}
+}
+
+// connect connects to the server and handles its messages until the connection
+// ends. It returns whether the server rejected the client, in which case there
+// is no use in trying again, and the reason why the connection ended.
+func (c *Client) connect(address, name string) (rejected bool, err error) {
+	conn, err := net.DialTimeout("tcp", address, dialTimeout)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		conn.Close()
+		return true, nil
+	}
+	c.conn = newConnection(conn)
+	c.welcomed = false
+	token := c.token
+	c.mu.Unlock()
+
+	join := Message{Kind: JoinMessage, Name: name, Token: token}
+	if err := c.conn.send(join); err != nil {
+		return false, err
+	}
+	for {
+		msg, err := c.conn.receive()
+		if err != nil {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if !c.welcomed && c.err != nil {
+				// the server did not let us join and told us why
+				return true, c.err
+			}
+			return false, errConnectionLost
+		}
+		c.handle(msg)
+	}
+}
+
+func (c *Client) handle(msg Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch msg.Kind {
+	case WelcomeMessage:
+		c.seat = msg.Seat
+		c.token = msg.Token
+		c.welcomed = true
+		if c.status == Connecting {
+			c.status = InLobby
+		}
+	case LobbyMessage:
+		c.seats = msg.Seats
+	case SnapshotMessage:
+		c.view = msg.View
+		c.status = Playing
+		c.err = nil
+	case UpdateMessage:
+		if c.view != nil && msg.Diff != nil {
+			c.view.Apply(*msg.Diff)
+		}
+		c.err = nil
+	case ErrorMessage:
+		c.err = errors.New(msg.Error)
+	}
+}
+
+func (c *Client) disconnect(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status != Disconnected {
+		c.status = Disconnected
+		c.err = err
+	}
+}
+
+// Status returns how far the connection to the server got.
+func (c *Client) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// Err returns the reason for the disconnect or the last error that the server
+// reported, e.g. when an action was rejected. It is nil if everything is fine.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Token returns the session token that the server gave this client. It can be
+// passed to Join to get back to the same seat, e.g. after a restart.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// Seat returns the color of the player that this client plays. It is only
+// valid once the status is InLobby or Playing.
+func (c *Client) Seat() game.Color {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seat
+}
+
+// Seats returns the open seats of the game as last sent by the server.
+func (c *Client) Seats() []SeatInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]SeatInfo(nil), c.seats...)
+}
+
+// Game returns the game as this client sees it, the other players' hands are
+// empty. It returns nil until the game has started.
+func (c *Client) Game() *game.Game {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.view == nil {
+		return nil
+	}
+	return c.view.Game()
+}
+
+// Do sends an action to the server. The game only changes once the server
+// accepted the action, if it is rejected, Err will tell why.
+func (c *Client) Do(a Action) error {
+	c.mu.Lock()
+	conn, status := c.conn, c.status
+	c.mu.Unlock()
+	if status == Reconnecting {
+		return errConnectionLost
+	}
+	if status != Playing {
+		return errNotStarted
+	}
+	return conn.send(Message{Kind: ActionMessage, Action: &a})
+}
+
+// Close disconnects from the server.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	c.status = Disconnected
+	if c.conn != nil {
+		return c.conn.close()
+	}
+	return nil
+}
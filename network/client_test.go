@@ -0,0 +1,135 @@
+package network
+
+import (
+	"github.com/gonutz/settlers/game"
+	"testing"
+)
+
+func TestClientJoinsAndPlays(t *testing.T) {
+	server := listen(t, game.Blue, game.White)
+	defer server.Close()
+
+	blue := Join(server.Addr().String(), "blue", "")
+	defer blue.Close()
+	waitFor(t, func() bool { return blue.Status() == InLobby })
+	white := Join(server.Addr().String(), "white", "")
+	defer white.Close()
+	waitFor(t, func() bool { return white.Status() == InLobby })
+	if blue.Seat() != game.Blue || white.Seat() != game.White {
+		t.Errorf("wrong seats %v %v", blue.Seat(), white.Seat())
+	}
+	waitFor(t, func() bool {
+		seats := blue.Seats()
+		return len(seats) == 2 && seats[1].Taken && seats[1].Name == "white"
+	})
+	if blue.Game() != nil {
+		t.Error("there should be no game before the start")
+	}
+	if err := blue.Do(Action{Kind: RollDice}); err != errNotStarted {
+		t.Errorf("action before start returned %v", err)
+	}
+
+	g := game.New([]game.Color{game.Blue, game.White}, 0)
+	g.Start()
+	server.Start(g)
+	waitFor(t, func() bool { return blue.Status() == Playing && white.Status() == Playing })
+
+	clients := map[game.Color]*Client{game.Blue: blue, game.White: white}
+	current := g.GetCurrentPlayer().Color
+	client := clients[current]
+
+	client.Do(Action{Kind: BuildRoad, Edge: game.TileEdge{X: 9, Y: 2}})
+	waitFor(t, func() bool { return client.Err() != nil })
+	if client.Err().Error() != errIllegalAction.Error() {
+		t.Errorf("rejected action gave error %v", client.Err())
+	}
+
+	corner := firstFreeCorner(client.Game())
+	client.Do(Action{Kind: BuildSettlement, Corner: corner})
+	for _, c := range clients {
+		waitFor(t, func() bool { return c.Game().State == game.BuildingFirstRoad })
+		built := c.Game().GetCurrentPlayer().GetBuiltSettlements()
+		if len(built) != 1 || built[0].Position != corner {
+			t.Errorf("client does not see the new settlement: %v", built)
+		}
+	}
+	if client.Err() != nil {
+		t.Errorf("accepted action should clear the error but have %v", client.Err())
+	}
+}
+
+func TestClientReportsFailedConnection(t *testing.T) {
+	server := listen(t)
+	address := server.Addr().String()
+	server.Close()
+
+	c := Join(address, "", "")
+	defer c.Close()
+	waitFor(t, func() bool { return c.Status() == Disconnected })
+	if c.Err() == nil {
+		t.Error("failed connection should have an error")
+	}
+}
+
+func TestClientNoticesServerShutdown(t *testing.T) {
+	server := listen(t, game.Red)
+	c := Join(server.Addr().String(), "", "")
+	defer c.Close()
+	waitFor(t, func() bool { return c.Status() == InLobby })
+	server.Close()
+	waitFor(t, func() bool { return c.Status() == Disconnected })
+	if c.Err() != errConnectionLost {
+		t.Errorf("wanted lost connection but got %v", c.Err())
+	}
+}
+
+func TestClientReconnectsAfterLosingConnection(t *testing.T) {
+	server := listen(t, game.Red)
+	defer server.Close()
+	c := Join(server.Addr().String(), "red", "")
+	defer c.Close()
+	waitFor(t, func() bool { return c.Status() == InLobby })
+	g := game.New([]game.Color{game.Red, game.Blue}, 0)
+	g.Players[0].Resources = [game.ResourceCount]int{1, 2, 3, 4, 5}
+	g.Players[1].Resources = [game.ResourceCount]int{1, 2, 3, 4, 5}
+	server.Start(g)
+	waitFor(t, func() bool { return c.Status() == Playing })
+	token := c.Token()
+
+	// cut the connection on the server side, as if the network went down
+	server.mu.Lock()
+	server.seatOf(game.Red).client.close()
+	server.mu.Unlock()
+
+	waitFor(t, func() bool { return c.Status() == Reconnecting })
+	if c.Game() == nil {
+		t.Error("the game should stay visible while reconnecting")
+	}
+	waitFor(t, func() bool { return c.Status() == Playing })
+	if c.Token() != token || c.Seat() != game.Red {
+		t.Errorf("client came back with token %v on seat %v", c.Token(), c.Seat())
+	}
+	for _, p := range c.Game().GetPlayers() {
+		if p.Color == game.Red && p.Resources != g.Players[0].Resources {
+			t.Errorf("reconnected client has hand %v", p.Resources)
+		}
+	}
+}
+
+func TestClientWithOldTokenGetsSeatBack(t *testing.T) {
+	server := listen(t, game.Red)
+	defer server.Close()
+	first := Join(server.Addr().String(), "red", "")
+	waitFor(t, func() bool { return first.Status() == InLobby })
+	server.Start(game.New([]game.Color{game.Red, game.Blue}, 0))
+	waitFor(t, func() bool { return first.Status() == Playing })
+	first.Close()
+
+	// a restarted program joins again with the token it saved
+	second := Join(server.Addr().String(), "red", first.Token())
+	defer second.Close()
+	waitFor(t, func() bool { return second.Status() == Playing })
+	if second.Seat() != game.Red {
+		t.Errorf("got seat %v", second.Seat())
+	}
+}
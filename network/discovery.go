@@ -0,0 +1,190 @@
+package network
+
+import (
+	"encoding/json"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Version is the version of the network protocol. It is announced with every
+// game so players can see which games they can join.
+const Version = 1
+
+// DiscoveryPort is the UDP port that hosts announce their games on.
+const DiscoveryPort = 5556
+
+var (
+	// BroadcastAddress sends announcements to every computer in the local
+	// network.
+	BroadcastAddress = net.JoinHostPort("255.255.255.255", strconv.Itoa(DiscoveryPort))
+	// DiscoveryAddress is where a Browser listens for announcements.
+	DiscoveryAddress = ":" + strconv.Itoa(DiscoveryPort)
+)
+
+const (
+	announceInterval = time.Second
+	// gameTimeout is how long a game is listed after its last announcement.
+	gameTimeout = 3 * announceInterval
+	// discoveryTag is sent with every announcement so other programs that
+	// happen to use the same port are ignored.
+	discoveryTag = "settlers"
+)
+
+type announcement struct {
+	Tag       string
+	Version   int
+	Name      string
+	Port      int
+	FreeSeats int
+}
+
+// GameInfo describes a game that was found in the local network.
+type GameInfo struct {
+	Name string
+	// Address is the host's address as it can be passed to Join.
+	Address   string
+	FreeSeats int
+	Version   int
+}
+
+// Announce tells other computers about the game until the server is closed.
+// Every second it sends the game's name and the number of free seats to the
+// given UDP address, usually BroadcastAddress.
+func (s *Server) Announce(name, target string) error {
+	addr, err := net.ResolveUDPAddr("udp", target)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return err
+	}
+	go s.announce(conn, name)
+	return nil
+}
+
+func (s *Server) announce(conn *net.UDPConn, name string) {
+	defer conn.Close()
+	a := announcement{
+		Tag:     discoveryTag,
+		Version: Version,
+		Name:    name,
+		Port:    s.listener.Addr().(*net.TCPAddr).Port,
+	}
+	for {
+		s.mu.Lock()
+		a.FreeSeats = s.freeSeats()
+		s.mu.Unlock()
+		data, err := json.Marshal(a)
+		if err == nil {
+			// there might be no network right now, in that case there is
+			// nobody to find the game anyway, just keep trying
+			conn.Write(data)
+		}
+		select {
+		case <-s.done:
+			return
+		case <-time.After(announceInterval):
+		}
+	}
+}
+
+// freeSeats returns how many more clients can join. Once the game has started
+// there are none.
+func (s *Server) freeSeats() int {
+	if s.game != nil {
+		return 0
+	}
+	free := 0
+	for _, seat := range s.seats {
+		if seat.token == "" {
+			free++
+		}
+	}
+	return free
+}
+
+// Browser collects the games that are announced in the local network.
+type Browser struct {
+	conn  *net.UDPConn
+	mu    sync.Mutex
+	games map[string]foundGame
+}
+
+type foundGame struct {
+	info GameInfo
+	seen time.Time
+}
+
+// Browse starts listening for announced games on the given UDP address,
+// usually DiscoveryAddress.
+func Browse(address string) (*Browser, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	b := &Browser{conn: conn, games: make(map[string]foundGame)}
+	go b.listen()
+	return b, nil
+}
+
+func (b *Browser) listen() {
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := b.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		var a announcement
+		if json.Unmarshal(buf[:n], &a) != nil || a.Tag != discoveryTag {
+			continue
+		}
+		info := GameInfo{
+			Name:      a.Name,
+			Address:   net.JoinHostPort(from.IP.String(), strconv.Itoa(a.Port)),
+			FreeSeats: a.FreeSeats,
+			Version:   a.Version,
+		}
+		b.mu.Lock()
+		b.games[info.Address] = foundGame{info: info, seen: time.Now()}
+		b.mu.Unlock()
+	}
+}
+
+// Addr returns the address that the browser listens on.
+func (b *Browser) Addr() net.Addr {
+	return b.conn.LocalAddr()
+}
+
+// Games returns the games that were announced recently, sorted by name.
+func (b *Browser) Games() []GameInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var games []GameInfo
+	for address, g := range b.games {
+		if time.Since(g.seen) > gameTimeout {
+			delete(b.games, address) // the host is gone
+		} else {
+			games = append(games, g.info)
+		}
+	}
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].Name != games[j].Name {
+			return games[i].Name < games[j].Name
+		}
+		return games[i].Address < games[j].Address
+	})
+	return games
+}
+
+// Close stops listening for announcements.
+func (b *Browser) Close() error {
+	return b.conn.Close()
+}
@@ -0,0 +1,101 @@
+package network
+
+import (
+	"github.com/gonutz/settlers/game"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestAnnouncedGamesAreFound(t *testing.T) {
+	browser := browse(t)
+	defer browser.Close()
+	server := listen(t, game.Red, game.Blue)
+	defer server.Close()
+	if err := server.Announce("Anna", browser.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return len(browser.Games()) == 1 })
+	found := browser.Games()[0]
+	if found.Name != "Anna" || found.FreeSeats != 2 || found.Version != Version {
+		t.Errorf("found game %v", found)
+	}
+
+	c := Join(found.Address, "Bert", "")
+	defer c.Close()
+	waitFor(t, func() bool { return c.Status() == InLobby })
+	waitFor(t, func() bool {
+		games := browser.Games()
+		return len(games) == 1 && games[0].FreeSeats == 1
+	})
+
+	server.Start(game.New([]game.Color{game.Red, game.Blue}, 0))
+	waitFor(t, func() bool { return browser.Games()[0].FreeSeats == 0 })
+}
+
+func TestBrowserListsGamesByName(t *testing.T) {
+	browser := browse(t)
+	defer browser.Close()
+	for _, name := range []string{"B", "C", "A"} {
+		server := listen(t, game.Red)
+		defer server.Close()
+		server.Announce(name, browser.Addr().String())
+	}
+
+	waitFor(t, func() bool { return len(browser.Games()) == 3 })
+	games := browser.Games()
+	if games[0].Name != "A" || games[1].Name != "B" || games[2].Name != "C" {
+		t.Errorf("games are not sorted: %v", games)
+	}
+}
+
+func TestBrowserIgnoresOtherPackets(t *testing.T) {
+	browser := browse(t)
+	defer browser.Close()
+	conn, err := net.Dial("udp", browser.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("hello"))
+	conn.Write([]byte(`{"Tag":"other game","Name":"X","Port":1}`))
+	conn.Write([]byte(`{"Tag":"settlers","Name":"valid","Port":1}`))
+
+	waitFor(t, func() bool { return len(browser.Games()) > 0 })
+	if games := browser.Games(); len(games) != 1 || games[0].Name != "valid" {
+		t.Errorf("found %v", games)
+	}
+}
+
+func TestGamesDisappearWhenNotAnnouncedAnymore(t *testing.T) {
+	browser := browse(t)
+	defer browser.Close()
+	conn, err := net.Dial("udp", browser.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte(`{"Tag":"settlers","Name":"gone","Port":1}`))
+	waitFor(t, func() bool { return len(browser.Games()) == 1 })
+
+	// pretend the timeout has passed
+	browser.mu.Lock()
+	for address, g := range browser.games {
+		g.seen = g.seen.Add(-gameTimeout - time.Second)
+		browser.games[address] = g
+	}
+	browser.mu.Unlock()
+
+	if games := browser.Games(); len(games) != 0 {
+		t.Errorf("old game is still listed: %v", games)
+	}
+}
+
+func browse(t *testing.T) *Browser {
+	browser, err := Browse("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return browser
+}
@@ -0,0 +1,351 @@
+// Package network lets players on different computers play one game together.
+// One computer hosts the game and runs the Server which holds the only real
+// game.Game. All other players connect to it, send the actions they want to do
+// and get back the resulting changes. Every client only sees what its player
+// would see at a real table, the other players' hands stay hidden.
+//
+// Messages are sent as JSON objects over TCP, one after the other.
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gonutz/settlers/game"
+	"net"
+	"sync"
+	"time"
+)
+
+type MessageKind int
+
+const (
+	// JoinMessage is sent by a client right after connecting, it asks for a
+	// seat and carries the player's Name. A client that lost its connection
+	// sends the Token it got before to take back its seat.
+	JoinMessage MessageKind = iota
+	// WelcomeMessage tells a client which Seat it got and the session Token
+	// that it needs to reconnect to this seat.
+	WelcomeMessage
+	// LobbyMessage is sent to all clients whenever the Seats change.
+	LobbyMessage
+	// SnapshotMessage carries the whole game in View, as seen by the
+	// receiving client.
+	SnapshotMessage
+	// ActionMessage is sent by a client to do an Action. The server answers
+	// with an UpdateMessage to everybody or an ErrorMessage to the sender.
+	ActionMessage
+	// UpdateMessage tells all clients which Action the player on Seat did and
+	// what changed because of it, in Diff.
+	UpdateMessage
+	// ErrorMessage is sent to a client when its request was rejected, Error
+	// says why.
+	ErrorMessage
+)
+
+type Message struct {
+	Kind   MessageKind
+	Seat   game.Color `json:",omitempty"`
+	Name   string     `json:",omitempty"`
+	Token  string     `json:",omitempty"`
+	Seats  []SeatInfo `json:",omitempty"`
+	Action *Action    `json:",omitempty"`
+	View   *GameView  `json:",omitempty"`
+	Diff   *Diff      `json:",omitempty"`
+	Error  string     `json:",omitempty"`
+}
+
+// SeatInfo describes one seat that can be taken by a network player. Seats are
+// identified by the color of the player sitting there, the player order is
+// only decided when the game starts.
+// Once the game has started, a seat stays Taken when its player loses the
+// connection, it is only not Connected until the player comes back.
+type SeatInfo struct {
+	Color     game.Color
+	Name      string
+	Taken     bool
+	Connected bool
+}
+
+type ActionKind int
+
+const (
+	RollDice ActionKind = iota
+	BuildSettlement
+	BuildRoad
+	BuildCity
+	BuyRoad
+	BuySettlement
+	BuyCity
+	BuyDevelopmentCard
+	EndTurn
+)
+
+// Action is something a player wants to do in the game. Corner is only used
+// for building settlements and cities, Edge only for building roads.
+type Action struct {
+	Kind   ActionKind
+	Corner game.TileCorner `json:",omitempty"`
+	Edge   game.TileEdge   `json:",omitempty"`
+}
+
+var (
+	errNotYourTurn   = errors.New("it is not your turn")
+	errIllegalAction = errors.New("this action is not allowed right now")
+	errNotStarted    = errors.New("the game has not started yet")
+)
+
+// Apply does the action in the game if it is legal for the current player,
+// otherwise it returns an error and leaves the game unchanged.
+func Apply(g *game.Game, a Action) error {
+	legal := false
+	switch a.Kind {
+	case RollDice:
+		legal = g.State == game.RollingDice
+		if legal {
+			g.RollTheDice()
+		}
+	case BuildSettlement:
+		legal = (g.State == game.BuildingFirstSettlement ||
+			g.State == game.BuildingSecondSettlement ||
+			g.State == game.BuildingNewSettlement) &&
+			g.CanBuildSettlementAt(a.Corner)
+		if legal {
+			g.BuildSettlement(a.Corner)
+		}
+	case BuildRoad:
+		legal = (g.State == game.BuildingFirstRoad ||
+			g.State == game.BuildingSecondRoad ||
+			g.State == game.BuildingNewRoad) &&
+			g.CanBuildRoadAt(a.Edge)
+		if legal {
+			g.BuildRoad(a.Edge)
+		}
+	case BuildCity:
+		legal = g.State == game.BuildingNewCity && g.CanBuildCityAt(a.Corner)
+		if legal {
+			g.BuildCity(a.Corner)
+		}
+	case BuyRoad:
+		legal = g.State == game.ChoosingNextAction && g.CanBuyRoad()
+		if legal {
+			g.BuyRoad()
+		}
+	case BuySettlement:
+		legal = g.State == game.ChoosingNextAction && g.CanBuySettlement()
+		if legal {
+			g.BuySettlement()
+		}
+	case BuyCity:
+		legal = g.State == game.ChoosingNextAction && g.CanBuyCity()
+		if legal {
+			g.BuyCity()
+		}
+	case BuyDevelopmentCard:
+		legal = g.State == game.ChoosingNextAction && g.CanBuyDevelopmentCard()
+		if legal {
+			g.BuyDevelopmentCard()
+		}
+	case EndTurn:
+		legal = g.State == game.ChoosingNextAction
+		if legal {
+			g.NextTurn()
+		}
+	}
+	if !legal {
+		return errIllegalAction
+	}
+	return nil
+}
+
+// PlayerView is what one client may know about a player. Resources are only
+// filled in for the client's own player, for everybody else only the number of
+// cards in their hand is known.
+type PlayerView struct {
+	Color          game.Color
+	Roads          [15]game.Road
+	Settlements    [5]game.Settlement
+	Cities         [4]game.City
+	Resources      [game.ResourceCount]int
+	CardCount      int
+	PlayedKnights  int
+	HasLongestRoad bool
+	HasLargestArmy bool
+}
+
+// GameView is the whole game as one client may see it.
+type GameView struct {
+	State         game.State
+	Tiles         [37]game.Tile
+	Players       []PlayerView
+	CurrentPlayer int
+	Robber        game.Robber
+	CardsDealt    int
+	Dice          [2]int
+	Rolls         []game.Roll
+	Events        []game.Event
+}
+
+// Diff holds the changes to a GameView after an action. The small values are
+// always sent, of the Players only the ones that changed, by index.
+type Diff struct {
+	State         game.State
+	CurrentPlayer int
+	Robber        game.Robber
+	CardsDealt    int
+	Dice          [2]int
+	Players       map[int]PlayerView `json:",omitempty"`
+	// Rolls and Events are the ones that happened since the last update
+	Rolls  []game.Roll  `json:",omitempty"`
+	Events []game.Event `json:",omitempty"`
+}
+
+func newPlayerView(p game.Player, hidden bool) PlayerView {
+	v := PlayerView{
+		Color:          p.Color,
+		Roads:          p.Roads,
+		Settlements:    p.Settlements,
+		Cities:         p.Cities,
+		Resources:      p.Resources,
+		CardCount:      p.CardCount(),
+		PlayedKnights:  p.PlayedKnights,
+		HasLongestRoad: p.HasLongestRoad,
+		HasLargestArmy: p.HasLargestArmy,
+	}
+	if hidden {
+		v.Resources = [game.ResourceCount]int{}
+	}
+	return v
+}
+
+// newGameView creates the view of the game that the player with the given
+// color gets to see.
+func newGameView(g *game.Game, viewer game.Color) GameView {
+	v := GameView{
+		State:         g.State,
+		Tiles:         g.Tiles,
+		CurrentPlayer: g.CurrentPlayer,
+		Robber:        g.Robber,
+		CardsDealt:    g.CardsDealt,
+		Dice:          g.Dice,
+		Rolls:         g.Rolls,
+		Events:        g.Events,
+	}
+	for _, p := range g.GetPlayers() {
+		v.Players = append(v.Players, newPlayerView(p, p.Color != viewer))
+	}
+	return v
+}
+
+// newDiff returns what changed from before to after, as seen by the player
+// with the given color.
+func newDiff(before, after *game.Game, viewer game.Color) Diff {
+	d := Diff{
+		State:         after.State,
+		CurrentPlayer: after.CurrentPlayer,
+		Robber:        after.Robber,
+		CardsDealt:    after.CardsDealt,
+		Dice:          after.Dice,
+	}
+	if len(after.Rolls) > len(before.Rolls) {
+		d.Rolls = after.Rolls[len(before.Rolls):]
+	}
+	if len(after.Events) > len(before.Events) {
+		d.Events = after.Events[len(before.Events):]
+	}
+	for i, p := range after.GetPlayers() {
+		if p != before.Players[i] {
+			if d.Players == nil {
+				d.Players = make(map[int]PlayerView)
+			}
+			d.Players[i] = newPlayerView(p, p.Color != viewer)
+		}
+	}
+	return d
+}
+
+// Game creates a game from the view. It can be used to draw the game and to
+// check which actions are possible but it can not roll the dice, only the
+// server can do that.
+func (v *GameView) Game() *game.Game {
+	var g game.Game
+	g.State = v.State
+	g.Tiles = v.Tiles
+	g.PlayerCount = len(v.Players)
+	g.CurrentPlayer = v.CurrentPlayer
+	g.Robber = v.Robber
+	g.CardsDealt = v.CardsDealt
+	g.Dice = v.Dice
+	// the view only appends to its history so the game can share it, see
+	// game.Game.Clone
+	g.Rolls = v.Rolls[:len(v.Rolls):len(v.Rolls)]
+	g.Events = v.Events[:len(v.Events):len(v.Events)]
+	for i, p := range v.Players {
+		g.Players[i] = game.Player{
+			Color:          p.Color,
+			Roads:          p.Roads,
+			Settlements:    p.Settlements,
+			Cities:         p.Cities,
+			Resources:      p.Resources,
+			PlayedKnights:  p.PlayedKnights,
+			HasLongestRoad: p.HasLongestRoad,
+			HasLargestArmy: p.HasLargestArmy,
+		}
+		// only the number of other players' cards is known
+		g.Players[i].HiddenCards = p.CardCount - g.Players[i].CardCount()
+	}
+	g.Reindex()
+	return &g
+}
+
+// Apply changes the view according to the diff.
+func (v *GameView) Apply(d Diff) {
+	v.State = d.State
+	v.CurrentPlayer = d.CurrentPlayer
+	v.Robber = d.Robber
+	v.CardsDealt = d.CardsDealt
+	v.Dice = d.Dice
+	v.Rolls = append(v.Rolls, d.Rolls...)
+	v.Events = append(v.Events, d.Events...)
+	for i, p := range d.Players {
+		if 0 <= i && i < len(v.Players) {
+			v.Players[i] = p
+		}
+	}
+}
+
+// connection sends and receives Messages over a network connection. send may be
+// called from multiple goroutines.
+type connection struct {
+	conn    net.Conn
+	encoder *json.Encoder
+	decoder *json.Decoder
+	sendMu  sync.Mutex
+}
+
+func newConnection(conn net.Conn) *connection {
+	return &connection{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		decoder: json.NewDecoder(conn),
+	}
+}
+
+// sendTimeout is how long a message may take to be sent. If the other side
+// does not read it in time, the connection is considered broken.
+const sendTimeout = 5 * time.Second
+
+func (c *connection) send(msg Message) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(sendTimeout))
+	return c.encoder.Encode(msg)
+}
+
+func (c *connection) receive() (msg Message, err error) {
+	err = c.decoder.Decode(&msg)
+	return
+}
+
+func (c *connection) close() error {
+	return c.conn.Close()
+}
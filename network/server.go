@@ -0,0 +1,409 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/gonutz/settlers/game"
+	"net"
+	"sync"
+)
+
+// Server hosts a game for network players. Before the game starts, clients can
+// connect and take one of the open seats. Once Start is called, every client
+// gets its view of the game and from then on the server validates the clients'
+// actions and tells everybody what changed.
+// The host's own players do their actions through Do so the clients learn
+// about them as well.
+// When a network player loses the connection during the game, the seat is kept
+// for it. It can come back with the session token it got when joining and then
+// gets the whole game again. In the meantime the game waits for it, unless a
+// StandIn is set that plays for it.
+type Server struct {
+	listener net.Listener
+	mu       sync.Mutex
+	seats    []*seat
+	game     *game.Game
+	standIn  StandIn
+	closed   bool
+	// done is closed when the server is closed
+	done chan struct{}
+}
+
+type seat struct {
+	color game.Color
+	name  string
+	// token is the session token of the player on this seat, it is empty if
+	// the seat is free
+	token string
+	// client is nil while the player is not connected
+	client *remoteClient
+}
+
+// remoteClient queues the messages for a connected client and sends them from
+// its own goroutine, so the server never waits for a slow client while it
+// holds its lock. A client that falls so far behind that its queue overflows
+// is disconnected.
+type remoteClient struct {
+	*connection
+	outgoing  chan Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// outgoingQueueSize is how many messages may wait for a client before it is
+// dropped.
+const outgoingQueueSize = 256
+
+func newRemoteClient(conn *connection) *remoteClient {
+	c := &remoteClient{
+		connection: conn,
+		outgoing:   make(chan Message, outgoingQueueSize),
+		done:       make(chan struct{}),
+	}
+	go c.sendQueued()
+	return c
+}
+
+// enqueue adds the message to the client's queue without waiting.
+func (c *remoteClient) enqueue(msg Message) {
+	select {
+	case c.outgoing <- msg:
+	default:
+		c.close()
+	}
+}
+
+func (c *remoteClient) sendQueued() {
+	for {
+		select {
+		case msg := <-c.outgoing:
+			if err := c.connection.send(msg); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// close ends the connection, the messages that are still queued are dropped.
+func (c *remoteClient) close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.connection.close()
+	})
+	return err
+}
+
+// StandIn decides what to do for a player that lost the connection. It gets a
+// copy of the game and returns false if it does not want to act, the game then
+// waits for the player to come back.
+type StandIn func(g *game.Game) (Action, bool)
+
+// Listen opens a server on the given TCP address, e.g. ":5555". The given
+// colors are the seats that network players can take, all other players are
+// played on the host.
+func Listen(address string, openSeats []game.Color) (*Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: listener, done: make(chan struct{})}
+	for _, color := range openSeats {
+		s.seats = append(s.seats, &seat{color: color})
+	}
+	go s.acceptClients()
+	return s, nil
+}
+
+// Addr returns the address that the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops accepting new clients and disconnects all current ones.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		close(s.done)
+	}
+	s.closed = true
+	err := s.listener.Close()
+	for _, seat := range s.seats {
+		if seat.client != nil {
+			seat.client.close()
+			seat.client = nil
+		}
+	}
+	return err
+}
+
+// Seats returns the current state of the open seats.
+func (s *Server) Seats() []SeatInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seatInfos()
+}
+
+func (s *Server) seatInfos() []SeatInfo {
+	infos := make([]SeatInfo, len(s.seats))
+	for i, seat := range s.seats {
+		infos[i] = SeatInfo{
+			Color:     seat.color,
+			Name:      seat.name,
+			Taken:     seat.token != "",
+			Connected: seat.client != nil,
+		}
+	}
+	return infos
+}
+
+// SetStandIn sets who plays for disconnected players. With nil, which is the
+// default, the game pauses until they reconnect.
+func (s *Server) SetStandIn(standIn StandIn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.standIn = standIn
+	s.letStandInPlay()
+}
+
+// Start sends the game to all connected clients. From now on the server owns
+// the game, do not change it directly anymore, use Do instead.
+func (s *Server) Start(g *game.Game) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.game = g
+	for _, seat := range s.seats {
+		if seat.client != nil {
+			s.sendSnapshot(seat)
+		}
+	}
+	s.letStandInPlay()
+}
+
+// Game returns a copy of the current game, or nil if it has not started yet.
+func (s *Server) Game() *game.Game {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.game == nil {
+		return nil
+	}
+	return s.game.Clone()
+}
+
+// Do lets the player with the given color do an action in the game and tells
+// all clients about it. It is meant for the players on the host, network
+// players send their actions over the network.
+func (s *Server) Do(player game.Color, a Action) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.do(player, a); err != nil {
+		return err
+	}
+	s.letStandInPlay()
+	return nil
+}
+
+func (s *Server) do(player game.Color, a Action) error {
+	if s.game == nil {
+		return errNotStarted
+	}
+	if s.game.GetCurrentPlayer().Color != player {
+		return errNotYourTurn
+	}
+	before := s.game.Clone()
+	if err := Apply(s.game, a); err != nil {
+		return err
+	}
+	for _, seat := range s.seats {
+		if seat.client != nil {
+			diff := newDiff(before, s.game, seat.color)
+			s.send(seat, Message{
+				Kind:   UpdateMessage,
+				Seat:   player,
+				Action: &a,
+				Diff:   &diff,
+			})
+		}
+	}
+	return nil
+}
+
+func (s *Server) acceptClients() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(newRemoteClient(newConnection(conn)))
+	}
+}
+
+func (s *Server) serve(client *remoteClient) {
+	defer client.close()
+
+	msg, err := client.receive()
+	if err != nil {
+		return
+	}
+	if msg.Kind != JoinMessage {
+		client.send(Message{Kind: ErrorMessage, Error: "join the game first"})
+		return
+	}
+	seat, err := s.takeSeat(client, msg.Name, msg.Token)
+	if err != nil {
+		client.send(Message{Kind: ErrorMessage, Error: err.Error()})
+		return
+	}
+	defer s.leaveSeat(seat, client)
+
+	for {
+		msg, err := client.receive()
+		if err != nil {
+			return
+		}
+		if msg.Kind == ActionMessage && msg.Action != nil {
+			s.mu.Lock()
+			err := s.do(seat.color, *msg.Action)
+			if err != nil {
+				s.send(seat, Message{Kind: ErrorMessage, Error: err.Error()})
+			} else {
+				s.letStandInPlay()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+var (
+	errNoFreeSeat  = errors.New("there is no free seat in this game")
+	errGameRunning = errors.New("the game has already started")
+)
+
+// takeSeat gives the client the seat that belongs to the token. If there is
+// none, e.g. because the client joins for the first time, it gets a free seat
+// if the game has not yet started.
+func (s *Server) takeSeat(client *remoteClient, name, token string) (*seat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, errNoFreeSeat
+	}
+	for _, seat := range s.seats {
+		if token != "" && seat.token == token {
+			if seat.client != nil {
+				// the old connection is still open but apparently the client
+				// lost it, e.g. when its computer went to sleep
+				seat.client.close()
+			}
+			seat.client = client
+			s.welcome(seat)
+			return seat, nil
+		}
+	}
+	if s.game != nil {
+		return nil, errGameRunning
+	}
+	for _, seat := range s.seats {
+		if seat.token == "" {
+			token, err := newToken()
+			if err != nil {
+				return nil, err
+			}
+			seat.token = token
+			seat.client = client
+			seat.name = name
+			s.welcome(seat)
+			return seat, nil
+		}
+	}
+	return nil, errNoFreeSeat
+}
+
+func (s *Server) welcome(seat *seat) {
+	s.send(seat, Message{Kind: WelcomeMessage, Seat: seat.color, Token: seat.token})
+	if s.game != nil {
+		s.sendSnapshot(seat)
+	}
+	s.broadcastLobby()
+}
+
+func newToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// leaveSeat is called when the client's connection ends. Before the game
+// starts, the seat becomes free again. In a running game it is kept for the
+// player to come back.
+func (s *Server) leaveSeat(seat *seat, client *remoteClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seat.client != client {
+		return // the server was closed or the client reconnected meanwhile
+	}
+	seat.client = nil
+	if s.game == nil {
+		seat.token = ""
+		seat.name = ""
+	}
+	s.broadcastLobby()
+	s.letStandInPlay()
+}
+
+// maxStandInMoves limits how long a stand-in can play at a time, so the server
+// does not hang if nobody is connected anymore.
+const maxStandInMoves = 1000
+
+// letStandInPlay plays for disconnected players as long as it is their turn.
+func (s *Server) letStandInPlay() {
+	if s.standIn == nil || s.game == nil {
+		return
+	}
+	for i := 0; i < maxStandInMoves; i++ {
+		seat := s.seatOf(s.game.GetCurrentPlayer().Color)
+		if seat == nil || seat.client != nil {
+			return
+		}
+		a, ok := s.standIn(s.game.Clone())
+		if !ok || s.do(seat.color, a) != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) seatOf(color game.Color) *seat {
+	for _, seat := range s.seats {
+		if seat.color == color {
+			return seat
+		}
+	}
+	return nil
+}
+
+func (s *Server) broadcastLobby() {
+	infos := s.seatInfos()
+	for _, seat := range s.seats {
+		if seat.client != nil {
+			s.send(seat, Message{Kind: LobbyMessage, Seats: infos})
+		}
+	}
+}
+
+func (s *Server) sendSnapshot(seat *seat) {
+	view := newGameView(s.game, seat.color)
+	s.send(seat, Message{Kind: SnapshotMessage, Seat: seat.color, View: &view})
+}
+
+// send queues the message for the client on the seat. If it cannot be sent,
+// the client is disconnected, its serve loop will then free the seat.
+func (s *Server) send(seat *seat, msg Message) {
+	seat.client.enqueue(msg)
+}
@@ -0,0 +1,384 @@
+package network
+
+import (
+	"github.com/gonutz/settlers/game"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestClientsGetDifferentSeats(t *testing.T) {
+	server := listen(t, game.Red, game.Blue)
+	defer server.Close()
+
+	red := join(t, server, "Anna")
+	defer red.close()
+	expectSeat(t, red, game.Red)
+	blue := join(t, server, "Bert")
+	defer blue.close()
+	expectSeat(t, blue, game.Blue)
+
+	third := dial(t, server)
+	defer third.close()
+	third.send(Message{Kind: JoinMessage, Name: "Carl"})
+	if msg := receiveKind(t, third, ErrorMessage); msg.Error != errNoFreeSeat.Error() {
+		t.Errorf("third client got error %q", msg.Error)
+	}
+
+	seats := server.Seats()
+	if len(seats) != 2 || !seats[0].Taken || seats[0].Name != "Anna" ||
+		!seats[1].Taken || seats[1].Name != "Bert" {
+		t.Errorf("unexpected seats %v", seats)
+	}
+}
+
+func TestLeavingFreesTheSeat(t *testing.T) {
+	server := listen(t, game.Red)
+	defer server.Close()
+
+	first := join(t, server, "first")
+	expectSeat(t, first, game.Red)
+	first.close()
+
+	waitFor(t, func() bool { return !server.Seats()[0].Taken })
+	second := join(t, server, "second")
+	defer second.close()
+	expectSeat(t, second, game.Red)
+}
+
+func TestClientsOnlySeeTheirOwnHand(t *testing.T) {
+	server := listen(t, game.Red, game.Blue)
+	defer server.Close()
+	red := join(t, server, "red")
+	defer red.close()
+	expectSeat(t, red, game.Red)
+	blue := join(t, server, "blue")
+	defer blue.close()
+	expectSeat(t, blue, game.Blue)
+
+	g := game.New([]game.Color{game.Red, game.Blue, game.White}, 0)
+	for i := range g.Players {
+		g.Players[i].Resources = [game.ResourceCount]int{1, 2, 3, 4, 5}
+	}
+	server.Start(g)
+
+	for _, c := range []struct {
+		client *connection
+		color  game.Color
+	}{{red, game.Red}, {blue, game.Blue}} {
+		view := receiveKind(t, c.client, SnapshotMessage).View
+		if len(view.Players) != 3 {
+			t.Fatalf("%v sees %v players", c.color, len(view.Players))
+		}
+		for _, p := range view.Players {
+			if p.CardCount != 15 {
+				t.Errorf("%v sees %v cards for %v", c.color, p.CardCount, p.Color)
+			}
+			hidden := p.Resources == [game.ResourceCount]int{}
+			if p.Color == c.color && hidden {
+				t.Errorf("%v can not see its own hand", c.color)
+			}
+			if p.Color != c.color && !hidden {
+				t.Errorf("%v can see the hand of %v", c.color, p.Color)
+			}
+		}
+		for _, p := range view.Game().GetPlayers() {
+			if p.CardCount() != 15 {
+				t.Errorf("%v's game has %v cards for %v", c.color, p.CardCount(), p.Color)
+			}
+		}
+	}
+}
+
+func TestActionsAreValidatedAndBroadcast(t *testing.T) {
+	server := listen(t, game.Red, game.Blue, game.White)
+	defer server.Close()
+	clients := make(map[game.Color]*connection)
+	for _, color := range []game.Color{game.Red, game.Blue, game.White} {
+		c := join(t, server, "")
+		defer c.close()
+		expectSeat(t, c, color)
+		clients[color] = c
+	}
+
+	g := game.New([]game.Color{game.Red, game.Blue, game.White}, 0)
+	g.Start()
+	server.Start(g)
+	views := make(map[game.Color]*GameView)
+	for color, c := range clients {
+		views[color] = receiveKind(t, c, SnapshotMessage).View
+	}
+
+	current := g.GetCurrentPlayer().Color
+	var waiting game.Color
+	for color := range clients {
+		if color != current {
+			waiting = color
+		}
+	}
+	corner := firstFreeCorner(views[current].Game())
+
+	clients[waiting].send(Message{Kind: ActionMessage, Action: &Action{
+		Kind: BuildSettlement, Corner: corner,
+	}})
+	if msg := receiveKind(t, clients[waiting], ErrorMessage); msg.Error != errNotYourTurn.Error() {
+		t.Errorf("player out of turn got error %q", msg.Error)
+	}
+
+	clients[current].send(Message{Kind: ActionMessage, Action: &Action{
+		Kind: BuildRoad, Edge: game.TileEdge{X: 9, Y: 2},
+	}})
+	if msg := receiveKind(t, clients[current], ErrorMessage); msg.Error != errIllegalAction.Error() {
+		t.Errorf("illegal action got error %q", msg.Error)
+	}
+
+	clients[current].send(Message{Kind: ActionMessage, Action: &Action{
+		Kind: BuildSettlement, Corner: corner,
+	}})
+	for color, c := range clients {
+		msg := receiveKind(t, c, UpdateMessage)
+		if msg.Seat != current || msg.Action.Kind != BuildSettlement {
+			t.Errorf("%v got wrong update %v %v", color, msg.Seat, msg.Action)
+		}
+		views[color].Apply(*msg.Diff)
+		built := views[color].Game().GetCurrentPlayer().GetBuiltSettlements()
+		if len(built) != 1 || built[0].Position != corner {
+			t.Errorf("%v does not see the new settlement: %v", color, built)
+		}
+		if views[color].State != game.BuildingFirstRoad {
+			t.Errorf("%v sees state %v", color, views[color].State)
+		}
+		events := views[color].Game().Events
+		if len(events) != 1 || events[0].Kind != game.SettlementBuilt || events[0].Player != current {
+			t.Errorf("%v sees events %v", color, events)
+		}
+	}
+}
+
+func TestHostActionsAreBroadcast(t *testing.T) {
+	server := listen(t, game.Blue)
+	defer server.Close()
+	blue := join(t, server, "")
+	defer blue.close()
+	expectSeat(t, blue, game.Blue)
+
+	if err := server.Do(game.Red, Action{Kind: RollDice}); err != errNotStarted {
+		t.Errorf("action before start returned %v", err)
+	}
+
+	g := game.New([]game.Color{game.Red, game.Blue}, 0)
+	g.Start()
+	server.Start(g)
+	receiveKind(t, blue, SnapshotMessage)
+	host := g.GetCurrentPlayer().Color
+	corner := firstFreeCorner(g)
+	if err := server.Do(host, Action{Kind: BuildSettlement, Corner: corner}); err != nil {
+		t.Fatal(err)
+	}
+	msg := receiveKind(t, blue, UpdateMessage)
+	if msg.Seat != host || msg.Action.Corner != corner {
+		t.Errorf("wrong update %v %v", msg.Seat, msg.Action)
+	}
+	if server.Game().State != game.BuildingFirstRoad {
+		t.Error("server game did not change")
+	}
+}
+
+func listen(t *testing.T, openSeats ...game.Color) *Server {
+	server, err := Listen("127.0.0.1:0", openSeats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func dial(t *testing.T, server *Server) *connection {
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newConnection(conn)
+}
+
+func join(t *testing.T, server *Server, name string) *connection {
+	c := dial(t, server)
+	if err := c.send(Message{Kind: JoinMessage, Name: name}); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func expectSeat(t *testing.T, c *connection, color game.Color) {
+	if msg := receiveKind(t, c, WelcomeMessage); msg.Seat != color {
+		t.Errorf("wanted seat %v but got %v", color, msg.Seat)
+	}
+}
+
+// receiveKind reads messages until one of the given kind arrives, lobby
+// updates that come in between are skipped.
+func receiveKind(t *testing.T, c *connection, kind MessageKind) Message {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		msg, err := c.receive()
+		if err != nil {
+			t.Fatalf("waiting for message %v: %v", kind, err)
+		}
+		if msg.Kind == kind {
+			return msg
+		}
+		if msg.Kind != LobbyMessage {
+			t.Fatalf("wanted message %v but got %v", kind, msg)
+		}
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for start := time.Now(); time.Since(start) < 5*time.Second; {
+		if condition() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("timed out")
+}
+
+func firstFreeCorner(g *game.Game) game.TileCorner {
+	for x := 0; x < 15; x++ {
+		for y := 0; y < 8; y++ {
+			if c := (game.TileCorner{X: x, Y: y}); g.CanBuildSettlementAt(c) {
+				return c
+			}
+		}
+	}
+	panic("no free corner")
+}
+
+func TestDisconnectedPlayerKeepsSeatAndCanComeBack(t *testing.T) {
+	server := listen(t, game.Red, game.Blue)
+	defer server.Close()
+	red := join(t, server, "red")
+	expectSeat(t, red, game.Red)
+	blue := join(t, server, "blue")
+	defer blue.close()
+	expectSeat(t, blue, game.Blue)
+
+	g := game.New([]game.Color{game.Red, game.Blue}, 0)
+	g.Players[0].Resources = [game.ResourceCount]int{1, 1, 1, 1, 1}
+	g.Players[1].Resources = [game.ResourceCount]int{1, 1, 1, 1, 1}
+	server.Start(g)
+	receiveKind(t, red, SnapshotMessage)
+
+	red.close()
+	waitFor(t, func() bool { return !server.Seats()[0].Connected })
+	if seat := server.Seats()[0]; !seat.Taken || seat.Name != "red" {
+		t.Errorf("disconnected seat was given up: %v", seat)
+	}
+
+	stranger := dial(t, server)
+	defer stranger.close()
+	stranger.send(Message{Kind: JoinMessage, Token: "unknown"})
+	if msg := receiveKind(t, stranger, ErrorMessage); msg.Error != errGameRunning.Error() {
+		t.Errorf("unknown token got error %q", msg.Error)
+	}
+
+	back := dial(t, server)
+	defer back.close()
+	back.send(Message{Kind: JoinMessage, Token: tokenOf(server, game.Red)})
+	expectSeat(t, back, game.Red)
+	view := receiveKind(t, back, SnapshotMessage).View
+	for _, p := range view.Players {
+		hidden := p.Resources == [game.ResourceCount]int{}
+		if p.Color == game.Red && hidden {
+			t.Error("reconnected player does not get its hand back")
+		}
+		if p.Color != game.Red && !hidden {
+			t.Error("reconnected player sees other hands")
+		}
+	}
+	waitFor(t, func() bool { return server.Seats()[0].Connected })
+}
+
+func TestLobbySeatIsFreedOnDisconnect(t *testing.T) {
+	server := listen(t, game.Red)
+	defer server.Close()
+	first := join(t, server, "first")
+	expectSeat(t, first, game.Red)
+	token := tokenOf(server, game.Red)
+	first.close()
+	waitFor(t, func() bool { return !server.Seats()[0].Taken })
+
+	second := join(t, server, "second")
+	defer second.close()
+	expectSeat(t, second, game.Red)
+	if tokenOf(server, game.Red) == token {
+		t.Error("new player got the old session token")
+	}
+}
+
+func TestStandInPlaysForDisconnectedPlayer(t *testing.T) {
+	server := listen(t, game.Red, game.Blue)
+	defer server.Close()
+	clients := make(map[game.Color]*connection)
+	for _, color := range []game.Color{game.Red, game.Blue} {
+		c := join(t, server, "")
+		defer c.close()
+		expectSeat(t, c, color)
+		clients[color] = c
+	}
+
+	g := game.New([]game.Color{game.Red, game.Blue}, 0)
+	g.Start()
+	server.Start(g)
+	gone := g.GetCurrentPlayer().Color
+	clients[gone].close()
+	waitFor(t, func() bool {
+		for _, seat := range server.Seats() {
+			if seat.Color == gone {
+				return !seat.Connected
+			}
+		}
+		return false
+	})
+	if server.Game().GetCurrentPlayer().Color != gone {
+		t.Fatal("without a stand-in the game must wait for the player")
+	}
+
+	server.SetStandIn(SimpleStandIn)
+	g = server.Game()
+	if g.GetCurrentPlayer().Color == gone {
+		t.Errorf("stand-in did not play, state is %v", g.State)
+	}
+	for _, p := range g.GetPlayers() {
+		if p.Color == gone &&
+			(len(p.GetBuiltSettlements()) != 1 || len(p.GetBuiltRoads()) != 1) {
+			t.Error("stand-in did not build the first settlement and road")
+		}
+	}
+}
+
+// tokenOf returns the session token of the seat, only the client on it would
+// normally know it.
+func tokenOf(server *Server, color game.Color) string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return server.seatOf(color).token
+}
+
+func TestClientThatDoesNotReadIsDroppedWithoutBlocking(t *testing.T) {
+	serverSide, clientSide := net.Pipe()
+	defer clientSide.Close()
+	// nobody reads from clientSide so the first message blocks the sender
+	client := newRemoteClient(newConnection(serverSide))
+	start := time.Now()
+	for i := 0; i < outgoingQueueSize+2; i++ {
+		client.enqueue(Message{Kind: LobbyMessage})
+	}
+	if time.Since(start) > time.Second {
+		t.Error("queueing messages waited for the client")
+	}
+	select {
+	case <-client.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the client was not dropped when its queue overflowed")
+	}
+}
@@ -0,0 +1,44 @@
+package network
+
+import "github.com/gonutz/settlers/game"
+
+// SimpleStandIn keeps the game going for a disconnected player while doing as
+// little as possible with its hand. It rolls the dice and ends the turn right
+// away. Pieces that have to be placed, in the opening or because the player
+// bought them right before losing the connection, go to the first free spot.
+func SimpleStandIn(g *game.Game) (Action, bool) {
+	w, h := g.Size()
+	switch g.State {
+	case game.RollingDice:
+		return Action{Kind: RollDice}, true
+	case game.ChoosingNextAction:
+		return Action{Kind: EndTurn}, true
+	case game.BuildingFirstSettlement, game.BuildingSecondSettlement,
+		game.BuildingNewSettlement, game.BuildingNewCity:
+		// corners reach one further than the tiles in both directions
+		for x := 0; x < w+2; x++ {
+			for y := 0; y < h+1; y++ {
+				corner := game.TileCorner{X: x, Y: y}
+				if g.State == game.BuildingNewCity {
+					if g.CanBuildCityAt(corner) {
+						return Action{Kind: BuildCity, Corner: corner}, true
+					}
+				} else if g.CanBuildSettlementAt(corner) {
+					return Action{Kind: BuildSettlement, Corner: corner}, true
+				}
+			}
+		}
+	case game.BuildingFirstRoad, game.BuildingSecondRoad, game.BuildingNewRoad:
+		// edges have twice the resolution of tiles in x, see
+		// game.AdjacentEdgesToTile
+		for x := 0; x < 2*w+3; x++ {
+			for y := 0; y < h+1; y++ {
+				edge := game.TileEdge{X: x, Y: y}
+				if g.CanBuildRoadAt(edge) {
+					return Action{Kind: BuildRoad, Edge: edge}, true
+				}
+			}
+		}
+	}
+	return Action{}, false
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/network"
+)
+
+// networkGame is a game that is played over the network. The UI does not change
+// such a game itself, it sends the players' actions to whoever owns the real
+// game and draws what comes back.
+type networkGame interface {
+	// Game returns a copy of the current game or nil if it has not started.
+	Game() *game.Game
+	// isLocalPlayer returns true if the player sits at this computer.
+	isLocalPlayer(color game.Color) bool
+	do(player game.Color, a network.Action) error
+	seats() []network.SeatInfo
+	close()
+}
+
+// hostedGame runs the server for a game on this computer. All players that are
+// not network players are played here.
+type hostedGame struct {
+	server *network.Server
+}
+
+func (h hostedGame) Game() *game.Game { return h.server.Game() }
+
+func (h hostedGame) isLocalPlayer(color game.Color) bool {
+	for _, seat := range h.server.Seats() {
+		if seat.Color == color {
+			return false
+		}
+	}
+	return true
+}
+
+func (h hostedGame) do(player game.Color, a network.Action) error {
+	return h.server.Do(player, a)
+}
+
+func (h hostedGame) seats() []network.SeatInfo { return h.server.Seats() }
+func (h hostedGame) close()                    { h.server.Close() }
+
+// joinedGame is a game hosted on another computer, only the client's own seat
+// is played here.
+type joinedGame struct {
+	client *network.Client
+}
+
+func (j joinedGame) Game() *game.Game { return j.client.Game() }
+
+func (j joinedGame) isLocalPlayer(color game.Color) bool {
+	return j.client.Status() == network.Playing && j.client.Seat() == color
+}
+
+func (j joinedGame) do(_ game.Color, a network.Action) error {
+	return j.client.Do(a)
+}
+
+func (j joinedGame) seats() []network.SeatInfo { return j.client.Seats() }
+func (j joinedGame) close()                    { j.client.Close() }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/settings"
+)
+
+// A palette gives the players their colors, all arrays are indexed by
+// game.Color. The classic palette has its own piece images, the others tint
+// the white pieces.
+type palette struct {
+	// pieces name the piece images, e.g. "red" for "settlement_red", and the
+	// images are multiplied with the tints.
+	pieces [4]string
+	tints  [4][4]float32
+	// light colors are the backgrounds in the panels and the seat list, full
+	// colors are the tabs and the exported boards and text colors are for the
+	// instructions.
+	light, full, text [4][4]float32
+}
+
+var palettes = map[string]*palette{
+	"classic": {
+		pieces: [4]string{"red", "white", "blue", "orange"},
+		tints:  [4][4]float32{{1, 1, 1, 1}, {1, 1, 1, 1}, {1, 1, 1, 1}, {1, 1, 1, 1}},
+		light:  [4][4]float32{{1, 0.5, 0.5, 1}, {1, 1, 1, 1}, {0.5, 0.5, 1, 1}, {1, 0.5, 0, 1}},
+		full:   [4][4]float32{{1, 0, 0, 1}, {1, 1, 1, 1}, {0, 0, 1, 1}, {1, 0.5, 0, 1}},
+		text:   [4][4]float32{{0.7, 0, 0, 1}, {1, 1, 1, 1}, {0, 0, 0.6, 1}, {1, 0.5, 0, 1}},
+	},
+	// okabeIto uses vermillion, blue and bluish green from the palette by Okabe
+	// and Ito that stays apart for all common kinds of color blindness.
+	"okabeIto": tintedPalette([4][4]float32{
+		{0.835, 0.369, 0, 1},
+		{1, 1, 1, 1},
+		{0, 0.447, 0.698, 1},
+		{0, 0.62, 0.451, 1},
+	}),
+	// tol uses red, blue and yellow from the bright scheme by Paul Tol.
+	"tol": tintedPalette([4][4]float32{
+		{0.933, 0.4, 0.467, 1},
+		{1, 1, 1, 1},
+		{0.267, 0.467, 0.667, 1},
+		{0.8, 0.733, 0.267, 1},
+	}),
+}
+
+var currentPalette = palettes["classic"]
+
+// tintedPalette colors the white pieces, the light colors are halfway to
+// white.
+func tintedPalette(colors [4][4]float32) *palette {
+	p := &palette{
+		pieces: [4]string{"white", "white", "white", "white"},
+		tints:  colors,
+		full:   colors,
+		text:   colors,
+	}
+	for i, c := range colors {
+		p.light[i] = [4]float32{(c[0] + 1) / 2, (c[1] + 1) / 2, (c[2] + 1) / 2, 1}
+	}
+	return p
+}
+
+func setPalette(name string) error {
+	p, ok := palettes[name]
+	if !ok {
+		return errors.New("unknown palette " + name)
+	}
+	currentPalette = p
+	return nil
+}
+
+// pieceTint is the color that the piece images of a player are drawn with.
+func pieceTint(color game.Color) [4]float32 {
+	return currentPalette.tints[color]
+}
+
+// playerMarkers are 5x5 patterns, one for every game.Color, that are drawn on
+// the pieces when the setting is on. They differ in shape so they do not rely
+// on the colors.
+var playerMarkers = [4][5]string{
+	{ // red: two bars
+		".....",
+		"#####",
+		".....",
+		"#####",
+		".....",
+	},
+	{ // white: a plus
+		"..#..",
+		"..#..",
+		"#####",
+		"..#..",
+		"..#..",
+	},
+	{ // blue: a ring
+		"#####",
+		"#...#",
+		"#...#",
+		"#...#",
+		"#####",
+	},
+	{ // orange: a cross
+		"#...#",
+		".#.#.",
+		"..#..",
+		".#.#.",
+		"#...#",
+	},
+}
+
+// drawPlayerMarker draws the marker of the player centered at x,y if the
+// setting is on. Every cell of the pattern is cell pixels wide, it is white
+// with a black outline so it shows on all colors.
+func (g *graphics) drawPlayerMarker(x, y int, color game.Color, cell int) {
+	if !settings.Settings.PlayerMarkers {
+		return
+	}
+	left, top := x-5*cell/2, y-5*cell/2
+	cells := func(outline int, c [4]float32) {
+		for row, line := range playerMarkers[color] {
+			for col, set := range line {
+				if set == '#' {
+					g.rect(left+col*cell-outline, top+row*cell-outline,
+						cell+2*outline, cell+2*outline, c)
+				}
+			}
+		}
+	}
+	cells(1, [4]float32{0, 0, 0, 1})
+	cells(0, [4]float32{1, 1, 1, 1})
+}
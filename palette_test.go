@@ -0,0 +1,57 @@
+package main
+
+import (
+	"github.com/gonutz/settlers/game"
+	"image"
+	"testing"
+)
+
+func TestEveryPaletteNamesExistingPieceImages(t *testing.T) {
+	defer setPalette("classic")
+	g, err := newSoftwareGraphics(image.NewRGBA(screenBounds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range palettes {
+		if err := setPalette(name); err != nil {
+			t.Fatal(err)
+		}
+		for c := game.Red; c <= game.Orange; c++ {
+			for _, id := range []string{
+				"settlement_" + colorToString(c),
+				"city_" + colorToString(c),
+				"road_" + colorToString(c) + "_up",
+			} {
+				if _, ok := g.images[id]; !ok {
+					t.Errorf("palette %s uses the missing image %s", name, id)
+				}
+			}
+		}
+	}
+}
+
+func TestPalettesTellRedAndOrangeApart(t *testing.T) {
+	defer setPalette("classic")
+	if colorToString(game.Red) != "red" || colorToString(game.Orange) != "orange" {
+		t.Error("the classic palette does not use the red and orange pieces")
+	}
+	for name := range palettes {
+		setPalette(name)
+		if pieceTint(game.Red) == pieceTint(game.Orange) &&
+			colorToString(game.Red) == colorToString(game.Orange) {
+			t.Errorf("red and orange pieces look the same in palette %s", name)
+		}
+	}
+	if err := setPalette("noSuchPalette"); err == nil {
+		t.Error("no error for an unknown palette")
+	}
+}
+
+func TestPlayerTabsFollowThePalette(t *testing.T) {
+	defer setPalette("classic")
+	tab := newPlayerTab(game.Red, newWindow(rect{}, newDummyLayout()), true)
+	setPalette("okabeIto")
+	if tab.color() != fullPlayerColor(game.Red) || tab.color() == palettes["classic"].full[game.Red] {
+		t.Errorf("tab color is %v after changing the palette", tab.color())
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/lang"
+	"strconv"
+)
+
+// playerPanel is what the info panel of one player shows. All of it is known
+// to every player, of the cards in the player's hand only the number is shown.
+type playerPanel struct {
+	name    string
+	player  game.Player
+	current bool
+}
+
+var (
+	panelBackColor        = [4]float32{0.8, 0.6, 0.5, 0.8}
+	panelCurrentBackColor = [4]float32{0.95, 0.8, 0.6, 0.95}
+	panelFrameColor       = [4]float32{1, 0.85, 0, 1}
+	panelFontColor        = [4]float32{0, 0, 0, 1}
+)
+
+const (
+	panelW       = 330
+	panelMargin  = 10
+	panelPadding = 15
+	panelLineH   = 50
+	panelIconsH  = 70
+	// collapsedPanelW is the width of the panels that only show the color and
+	// the victory points of the players
+	collapsedPanelW = 120
+	panelSwatch     = 28
+)
+
+// newPlayerPanelList creates the column of info panels at the right edge of
+// the HUD. When it would cover the board, it is collapsed to the colors and
+// victory points of the players. A click on it shows the whole panels over the
+// board until the next click. boardRight returns the right edge of the board
+// in HUD coordinates.
+func newPlayerPanelList(panels func() []playerPanel, boardRight func() int) *playerPanelList {
+	return &playerPanelList{
+		hudElement: hudElement{rect{0, 0, panelW, panelLineH}},
+		panels:     panels,
+		boardRight: boardRight,
+	}
+}
+
+type playerPanelList struct {
+	hudElement
+	panels     func() []playerPanel
+	boardRight func() int
+	// expanded is set while the player wants to see the whole panels even
+	// though they cover the board
+	expanded bool
+	// shown and collapsed are updated in every frame
+	shown     []playerPanel
+	collapsed bool
+}
+
+// update gets the panels to show and collapses them if they would cover the
+// board.
+func (l *playerPanelList) update() {
+	l.shown = l.panels()
+	l.collapsed = !l.expanded && l.x < l.boardRight()
+}
+
+// toggle shows the whole panels over the board or collapses them again.
+func (l *playerPanelList) toggle() {
+	l.expanded = !l.expanded
+}
+
+// shownRect returns the area that the panels are drawn in. They stay at the
+// right edge of the list's bounds.
+func (l *playerPanelList) shownRect() rect {
+	r := l.rect
+	r.h = 0
+	for _, p := range l.shown {
+		r.h += panelHeight(p, l.collapsed) + panelMargin
+	}
+	r.h -= panelMargin
+	if l.collapsed {
+		r.x, r.w = r.x+r.w-collapsedPanelW, collapsedPanelW
+	}
+	return r
+}
+
+func (l *playerPanelList) contains(x, y int) bool {
+	return len(l.shown) > 0 && l.shownRect().contains(x, y)
+}
+
+func (l *playerPanelList) draw(g *graphics) {
+	r := l.shownRect()
+	y := r.y
+	for _, p := range l.shown {
+		if l.collapsed {
+			g.drawCollapsedPlayerPanel(p, r.x, y)
+		} else {
+			g.drawPlayerPanel(p, r.x, y)
+		}
+		y += panelHeight(p, l.collapsed) + panelMargin
+	}
+}
+
+// panelHeight returns how high the panel of the player is drawn, the collapsed
+// panels only have their first line.
+func panelHeight(p playerPanel, collapsed bool) int {
+	if collapsed {
+		return panelLineH
+	}
+	return panelLineH + panelIconsH + panelLineH*(1+len(playerBadges(p.player)))
+}
+
+// drawPlayerPanel draws the panel with its top-left corner at x,y. The panel of
+// the current player gets a frame around it.
+func (g *graphics) drawPlayerPanel(p playerPanel, x, y int) {
+	badges := playerBadges(p.player)
+	h := panelHeight(p, false)
+	g.drawPanelBackground(p, x, y, panelW, h)
+	black := panelFontColor
+	left, right := x+panelPadding, x+panelW-panelPadding
+
+	// the color, the name and the victory points
+	centerY := y + panelLineH/2
+	g.drawPlayerSwatch(p.player.Color, left, centerY)
+	g.writeLeftAlignedVerticallyCenteredAt(p.name, left+panelSwatch+10, centerY, black)
+	points := strconv.Itoa(p.player.VictoryPoints()) + " " + lang.Get(lang.VictoryPointsShort)
+	pointsW, _ := g.TextSize(points)
+	g.writeLeftAlignedVerticallyCenteredAt(points, right-pointsW, centerY, black)
+
+	// the cards in the hand and the pieces left to build
+	centerY = y + panelLineH + panelIconsH/2
+	cellW := (right - left) / 4
+	counts := []int{
+		p.player.CardCount(),
+		p.player.RemainingRoads(),
+		p.player.RemainingSettlements(),
+		p.player.RemainingCities(),
+	}
+	for i, count := range counts {
+		iconX := left + i*cellW + 20
+		switch i {
+		case 0:
+			g.drawImageCenteredAt("card_symbol", iconX, centerY)
+		case 1:
+			g.rect(iconX-7, centerY-24, 14, 48, black)
+			g.rect(iconX-5, centerY-22, 10, 44, playerColor(p.player.Color))
+		case 2:
+			g.drawSettlementAt(iconX, centerY, p.player.Color)
+		case 3:
+			g.drawCityAt(iconX, centerY, p.player.Color)
+		}
+		g.writeLeftAlignedVerticallyCenteredAt(strconv.Itoa(count), iconX+25, centerY, black)
+	}
+
+	// the played knights and the special cards
+	centerY = y + panelLineH + panelIconsH + panelLineH/2
+	knights := lang.Get(lang.Knights) + " " + strconv.Itoa(p.player.PlayedKnights)
+	g.writeLeftAlignedVerticallyCenteredAt(knights, left, centerY, black)
+	for _, badge := range badges {
+		centerY += panelLineH
+		g.writeLeftAlignedVerticallyCenteredAt(badge, left, centerY, [4]float32{0.6, 0, 0, 1})
+	}
+}
+
+// drawCollapsedPlayerPanel draws only the color and the victory points of the
+// player with the top-left corner at x,y.
+func (g *graphics) drawCollapsedPlayerPanel(p playerPanel, x, y int) {
+	g.drawPanelBackground(p, x, y, collapsedPanelW, panelLineH)
+	centerY := y + panelLineH/2
+	g.drawPlayerSwatch(p.player.Color, x+panelPadding, centerY)
+	points := strconv.Itoa(p.player.VictoryPoints())
+	pointsW, _ := g.TextSize(points)
+	g.writeLeftAlignedVerticallyCenteredAt(points,
+		x+collapsedPanelW-panelPadding-pointsW, centerY, panelFontColor)
+}
+
+// drawPanelBackground fills the panel, the one of the current player gets a
+// frame around it.
+func (g *graphics) drawPanelBackground(p playerPanel, x, y, w, h int) {
+	background := panelBackColor
+	if p.current {
+		const frame = 4
+		g.rect(x-frame, y-frame, w+2*frame, h+2*frame, panelFrameColor)
+		background = panelCurrentBackColor
+	}
+	g.rect(x, y, w, h, background)
+}
+
+// drawPlayerSwatch draws the color of the player with the marker on it, left
+// of the swatch is at x.
+func (g *graphics) drawPlayerSwatch(color game.Color, x, centerY int) {
+	g.rect(x, centerY-panelSwatch/2, panelSwatch, panelSwatch, panelFontColor)
+	g.rect(x+2, centerY-panelSwatch/2+2, panelSwatch-4, panelSwatch-4, playerColor(color))
+	g.drawPlayerMarker(x+panelSwatch/2, centerY, color, 4)
+}
+
+func playerBadges(p game.Player) []string {
+	var badges []string
+	if p.HasLongestRoad {
+		badges = append(badges, lang.Get(lang.LongestRoad))
+	}
+	if p.HasLargestArmy {
+		badges = append(badges, lang.Get(lang.LargestArmy))
+	}
+	return badges
+}
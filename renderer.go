@@ -0,0 +1,35 @@
+package main
+
+import "image"
+
+var (
+	// fontFile and fontSize are set by the theme before the graphics are
+	// created, the font can not be changed afterwards.
+	fontFile = "MorrisRoman-Black.ttf"
+	fontSize = 45.0
+
+	clearColor = [4]float32{0, 0, 0.7, 1}
+)
+
+// renderer draws the basic shapes that everything on screen is made of. All
+// coordinates are game coordinates, see camera. Images are identified by the
+// IDs in images/table.txt.
+type renderer interface {
+	// clear fills the whole screen with the background color.
+	clear()
+	rect(x, y, w, h int, color [4]float32)
+	// image draws the image with its top-left corner at x,y.
+	image(id string, x, y int)
+	// coloredImage draws the image like image does but multiplies every pixel
+	// with the given color.
+	coloredImage(id string, x, y int, color [4]float32)
+	// text writes a line of text in the given color. x is the left end of the
+	// text and y is its baseline.
+	text(text string, x, y float64, color [4]float32)
+	TextSize(text string) (w, h int)
+	// fontSize is the height of a line of text.
+	fontSize() float64
+	// setImage adds a new image or replaces the one with the given ID, e.g.
+	// for the game's background which is created for every new game.
+	setImage(id string, img image.Image) error
+}
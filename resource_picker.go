@@ -0,0 +1,179 @@
+package main
+
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/gonutz/settlers/game"
+	"strconv"
+)
+
+var (
+	pickerBackColor  = [4]float32{0.8, 0.6, 0.5, 0.8}
+	pickerFocusColor = [4]float32{0.95, 0.8, 0.6, 0.95}
+	pickerCountColor = [4]float32{0, 0, 0, 1}
+	pickerLimitColor = [4]float32{0.4, 0.4, 0.4, 1}
+)
+
+var pickerButtonMargin = 10
+
+// newResourcePicker creates a widget to choose a number of each resource, e.g.
+// the cards to give in a trade or to discard. There is a column for every
+// resource with its symbol, the chosen count and +/- buttons. No count can go
+// above its limit, usually what the player owns.
+func newResourcePicker(bounds rect, limits [game.ResourceCount]int) *resourcePicker {
+	return &resourcePicker{rect: bounds, limits: limits}
+}
+
+type resourcePicker struct {
+	rect
+	limits [game.ResourceCount]int
+	counts [game.ResourceCount]int
+	// maxTotal limits the sum of all counts, e.g. to the number of cards to
+	// discard, 0 means there is no limit
+	maxTotal     int
+	changeAction func([game.ResourceCount]int)
+	focused      bool
+	// column is the resource that the arrow keys change
+	column int
+	// hot is the button under the mouse, it is empty if there is none
+	hot rect
+}
+
+func (p *resourcePicker) onChange(action func([game.ResourceCount]int)) {
+	p.changeAction = action
+}
+
+func (p *resourcePicker) setMaxTotal(n int) { p.maxTotal = n }
+
+// picked returns the chosen count of every resource.
+func (p *resourcePicker) picked() [game.ResourceCount]int { return p.counts }
+
+func (p *resourcePicker) total() int {
+	sum := 0
+	for _, n := range p.counts {
+		sum += n
+	}
+	return sum
+}
+
+func (p *resourcePicker) bounds() rect          { return p.rect }
+func (p *resourcePicker) setBounds(bounds rect) { p.rect = bounds }
+
+// cells returns the areas of the plus button, the symbol, the count and the
+// minus button of the resource's column, from top to bottom.
+func (p *resourcePicker) cells(r int) (plus, symbol, count, minus rect) {
+	w := p.w / game.ResourceCount
+	h := p.h / 4
+	x := p.x + r*w
+	plus = rect{x + pickerButtonMargin, p.y + pickerButtonMargin, w - 2*pickerButtonMargin, h - pickerButtonMargin}
+	symbol = rect{x, p.y + h, w, h}
+	count = rect{x, p.y + 2*h, w, h}
+	minus = rect{x + pickerButtonMargin, p.y + 3*h, w - 2*pickerButtonMargin, h - pickerButtonMargin}
+	return
+}
+
+func (p *resourcePicker) draw(g *graphics) {
+	g.rect(p.x, p.y, p.w, p.h, pickerBackColor)
+	for r := 0; r < game.ResourceCount; r++ {
+		plus, symbol, count, minus := p.cells(r)
+		if p.focused && r == p.column {
+			g.rect(symbol.x, p.y, symbol.w, p.h, pickerFocusColor)
+		}
+		p.drawButton(g, plus, "+", p.canChange(r, 1))
+		g.drawImageCenteredAt(resourceToString(game.Resource(r))+"_symbol",
+			symbol.x+symbol.w/2, symbol.y+symbol.h/2)
+		countColor := pickerCountColor
+		if p.counts[r] == p.limits[r] {
+			countColor = pickerLimitColor
+		}
+		text := strconv.Itoa(p.counts[r]) + "/" + strconv.Itoa(p.limits[r])
+		g.writeTextLineCenteredInRect(text, count, countColor)
+		p.drawButton(g, minus, "-", p.canChange(r, -1))
+	}
+}
+
+func (p *resourcePicker) drawButton(g *graphics, r rect, text string, enabled bool) {
+	color := menuColdBackColor
+	if p.hot == r && enabled {
+		color = menuHotBackColor
+	}
+	g.rect(r.x, r.y, r.w, r.h, color)
+	fontColor := menuFontColor
+	if !enabled {
+		fontColor = menuColdFontColor
+	}
+	g.writeTextLineCenteredInRect(text, r, fontColor)
+}
+
+func (p *resourcePicker) mouseMovedTo(x, y int) {
+	p.hot = rect{}
+	for r := 0; r < game.ResourceCount; r++ {
+		plus, _, _, minus := p.cells(r)
+		if plus.contains(x, y) {
+			p.hot = plus
+		}
+		if minus.contains(x, y) {
+			p.hot = minus
+		}
+	}
+}
+
+func (p *resourcePicker) click(x, y int) (actionID int) {
+	for r := 0; r < game.ResourceCount; r++ {
+		plus, symbol, count, minus := p.cells(r)
+		if plus.contains(x, y) {
+			p.change(r, 1)
+		}
+		if minus.contains(x, y) {
+			p.change(r, -1)
+		}
+		if plus.contains(x, y) || minus.contains(x, y) ||
+			symbol.contains(x, y) || count.contains(x, y) {
+			p.column = r
+		}
+	}
+	return -1
+}
+
+func (p *resourcePicker) runeTyped(rune) {}
+
+// keyPressed chooses the resource with Left and Right and changes its count
+// with Up and Down or plus and minus.
+func (p *resourcePicker) keyPressed(key glfw.Key, mods glfw.ModifierKey) (actionID int) {
+	switch key {
+	case glfw.KeyLeft:
+		if p.column > 0 {
+			p.column--
+		}
+	case glfw.KeyRight:
+		if p.column < game.ResourceCount-1 {
+			p.column++
+		}
+	case glfw.KeyUp, glfw.KeyKPAdd, glfw.KeyEqual:
+		p.change(p.column, 1)
+	case glfw.KeyDown, glfw.KeyKPSubtract, glfw.KeyMinus:
+		p.change(p.column, -1)
+	}
+	return -1
+}
+
+func (p *resourcePicker) canFocus() bool  { return true }
+func (p *resourcePicker) setFocus(f bool) { p.focused = f }
+func (p *resourcePicker) focusRect() rect { return p.rect }
+
+func (p *resourcePicker) canChange(r, delta int) bool {
+	n := p.counts[r] + delta
+	if n < 0 || n > p.limits[r] {
+		return false
+	}
+	return delta < 0 || p.maxTotal == 0 || p.total()+delta <= p.maxTotal
+}
+
+func (p *resourcePicker) change(r, delta int) {
+	if !p.canChange(r, delta) {
+		return
+	}
+	p.counts[r] += delta
+	if p.changeAction != nil {
+		p.changeAction(p.counts)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/gonutz/settlers/game"
+	"testing"
+)
+
+func TestResourcePickerStaysInsideTheLimits(t *testing.T) {
+	var owned [game.ResourceCount]int
+	owned[game.Ore] = 2
+	p := newResourcePicker(rect{0, 0, 500, 400}, owned)
+	var reported [game.ResourceCount]int
+	p.onChange(func(counts [game.ResourceCount]int) { reported = counts })
+
+	plus, _, _, minus := p.cells(int(game.Ore))
+	for i := 0; i < 3; i++ {
+		p.click(plus.x+1, plus.y+1)
+	}
+	if p.picked()[game.Ore] != 2 || reported[game.Ore] != 2 {
+		t.Errorf("picked %v of 2 ore, reported %v", p.picked(), reported)
+	}
+	for i := 0; i < 3; i++ {
+		p.click(minus.x+1, minus.y+1)
+	}
+	if p.picked()[game.Ore] != 0 {
+		t.Errorf("picked %v after taking all back", p.picked())
+	}
+
+	grainPlus, _, _, _ := p.cells(int(game.Grain))
+	p.click(grainPlus.x+1, grainPlus.y+1)
+	if p.picked()[game.Grain] != 0 {
+		t.Error("picked grain that the player does not own")
+	}
+}
+
+func TestResourcePickerLimitsTheTotal(t *testing.T) {
+	owned := [game.ResourceCount]int{3, 3, 3, 3, 3}
+	p := newResourcePicker(rect{0, 0, 500, 400}, owned)
+	p.setMaxTotal(4)
+	for _, key := range []glfw.Key{
+		glfw.KeyUp, glfw.KeyUp, glfw.KeyRight, glfw.KeyUp, glfw.KeyUp, glfw.KeyUp,
+	} {
+		p.keyPressed(key, 0)
+	}
+	if got := p.picked(); got[0] != 2 || got[1] != 2 || p.total() != 4 {
+		t.Errorf("picked %v with a maximum of 4", got)
+	}
+	p.keyPressed(glfw.KeyDown, 0)
+	p.keyPressed(glfw.KeyRight, 0)
+	p.keyPressed(glfw.KeyUp, 0)
+	if got := p.picked(); got[1] != 1 || got[2] != 1 {
+		t.Errorf("picked %v after moving one card", got)
+	}
+}
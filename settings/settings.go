@@ -12,6 +12,26 @@ type settings struct {
 	IPs         [4]string
 	Ports       [4]string
 	Language    int
+	HostPort    string
+	JoinName    string
+	JoinIP      string
+	JoinPort    string
+	// JoinToken is the session token of the last joined game, it lets the
+	// player get back to the seat after a restart.
+	JoinToken string
+	// AIForDisconnected lets the computer play for network players that lost
+	// the connection, otherwise the game waits for them.
+	AIForDisconnected bool
+	// Theme is the name of the file in the themes folder, without ".json".
+	Theme string
+	// UIScale makes the menus and the information around the board bigger.
+	UIScale float64
+	// Palette is the name of the player colors, see palettes in the main
+	// package.
+	Palette string
+	// PlayerMarkers draws a symbol for every player on the pieces and panels
+	// so the players can be told apart without their colors.
+	PlayerMarkers bool
 }
 
 var Settings = &settings{
@@ -21,6 +41,16 @@ var Settings = &settings{
 	[4]string{"127.0.0.1", "127.0.0.1", "127.0.0.1", "127.0.0.1"},
 	[4]string{"5555", "5555", "5555", "5555"},
 	0,
+	"5555",
+	"1",
+	"127.0.0.1",
+	"5555",
+	"",
+	false,
+	"default",
+	1,
+	"classic",
+	false,
 }
 
 const settingsPath = "./settings.txt"
@@ -32,7 +62,9 @@ func (s *settings) Load() error {
 	}
 	defer file.Close()
 
-	var loaded settings
+	// start with the current settings so values that are missing in the file,
+	// e.g. because it was written by an older version, keep their defaults
+	loaded := *s
 	decoder := json.NewDecoder(file)
 	err = decoder.Decode(&loaded)
 	if err != nil {
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"unicode/utf8"
+)
+
+// screenBounds contains everything that is drawn in game coordinates, that is
+// the game area and the borders around it where e.g. the instruction and the
+// resources are shown.
+var screenBounds = image.Rect(-leftBorder, -topBorder, gameW+rightBorder, gameH+bottomBorder)
+
+// softwareRenderer draws into an image in memory, it does not need a graphics
+// card. This way frames can be rendered without a window, e.g. in tests.
+// There is no font rasterizer, text is drawn as one block per character so the
+// layout is still visible.
+type softwareRenderer struct {
+	dest   *image.RGBA
+	images map[string]image.Image
+}
+
+// newSoftwareRenderer creates a renderer that draws into dest. The image's
+// bounds are game coordinates, to get the whole screen use screenBounds.
+func newSoftwareRenderer(dest *image.RGBA, images map[string]image.Image) *softwareRenderer {
+	r := &softwareRenderer{dest: dest, images: make(map[string]image.Image)}
+	for id, img := range images {
+		r.images[id] = img
+	}
+	return r
+}
+
+func (r *softwareRenderer) clear() {
+	draw.Draw(r.dest, r.dest.Bounds(), image.NewUniform(toNRGBA(clearColor)),
+		image.ZP, draw.Src)
+}
+
+func (r *softwareRenderer) rect(x, y, w, h int, c [4]float32) {
+	draw.Draw(r.dest, image.Rect(x, y, x+w, y+h), image.NewUniform(toNRGBA(c)),
+		image.ZP, draw.Over)
+}
+
+func toNRGBA(c [4]float32) color.NRGBA {
+	return color.NRGBA{toByte(c[0]), toByte(c[1]), toByte(c[2]), toByte(c[3])}
+}
+
+func toByte(f float32) uint8 {
+	if f <= 0 {
+		return 0
+	}
+	if f >= 1 {
+		return 255
+	}
+	return uint8(f*255 + 0.5)
+}
+
+func (r *softwareRenderer) image(id string, x, y int) {
+	img := r.getImage(id)
+	b := img.Bounds()
+	draw.Draw(r.dest, b.Sub(b.Min).Add(image.Pt(x, y)), img, b.Min, draw.Over)
+}
+
+func (r *softwareRenderer) coloredImage(id string, x, y int, c [4]float32) {
+	img := r.getImage(id)
+	b := img.Bounds()
+	colored := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for dy := 0; dy < b.Dy(); dy++ {
+		for dx := 0; dx < b.Dx(); dx++ {
+			// the colors are premultiplied with alpha, so is the result
+			red, green, blue, alpha := img.At(b.Min.X+dx, b.Min.Y+dy).RGBA()
+			colored.SetRGBA(dx, dy, color.RGBA{
+				R: uint8(float32(red>>8) * c[0] * c[3]),
+				G: uint8(float32(green>>8) * c[1] * c[3]),
+				B: uint8(float32(blue>>8) * c[2] * c[3]),
+				A: uint8(float32(alpha>>8) * c[3]),
+			})
+		}
+	}
+	draw.Draw(r.dest, colored.Bounds().Add(image.Pt(x, y)), colored, image.ZP, draw.Over)
+}
+
+func (r *softwareRenderer) getImage(id string) image.Image {
+	if img, ok := r.images[id]; ok {
+		return img
+	}
+	panic("illegal image ID: '" + id + "'")
+}
+
+func (r *softwareRenderer) text(text string, x, y float64, c [4]float32) {
+	advance := r.fontSize() / 2
+	h := int(r.fontSize() * 0.7)
+	for _, char := range text {
+		if char != ' ' {
+			r.rect(int(x)+2, int(y)-h, int(advance)-4, h, c)
+		}
+		x += advance
+	}
+}
+
+func (r *softwareRenderer) TextSize(text string) (w, h int) {
+	advance := r.fontSize() / 2
+	return int(float64(utf8.RuneCountInString(text))*advance + 0.5), int(r.fontSize() + 0.5)
+}
+
+func (r *softwareRenderer) fontSize() float64 {
+	return fontSize
+}
+
+func (r *softwareRenderer) setImage(id string, img image.Image) error {
+	r.images[id] = img
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSoftwareRendererDrawsAtlasImages(t *testing.T) {
+	g, dest := softwareGraphics(t)
+	g.renderer.clear()
+	g.renderer.image("die_1", 10, 20)
+
+	img := g.images["die_1"]
+	b := img.Bounds()
+	x, y := opaquePixel(t, img)
+	want := color.RGBAModel.Convert(img.At(x, y))
+	if got := dest.At(10+x-b.Min.X, 20+y-b.Min.Y); got != want {
+		t.Errorf("want %v but got %v", want, got)
+	}
+	if got := dest.At(9, 19); got != color.RGBAModel.Convert(toNRGBA(clearColor)) {
+		t.Errorf("image drawn outside its bounds: %v", got)
+	}
+}
+
+func TestSoftwareRendererBlendsRects(t *testing.T) {
+	g, dest := softwareGraphics(t)
+	g.renderer.rect(-50, -50, 100, 100, [4]float32{1, 0, 0, 1})
+	g.renderer.rect(0, 0, 100, 100, [4]float32{0, 0, 1, 0.5})
+
+	if got := dest.RGBAAt(-50, -50); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("solid rect has color %v", got)
+	}
+	if got := dest.RGBAAt(10, 10); got.R < 126 || got.R > 129 || got.B < 126 || got.B > 129 {
+		t.Errorf("half transparent rect was not blended: %v", got)
+	}
+	if got := dest.RGBAAt(100, 100); got != (color.RGBA{}) {
+		t.Errorf("rect drawn outside its bounds: %v", got)
+	}
+}
+
+func TestSoftwareRendererColorsImages(t *testing.T) {
+	g, dest := softwareGraphics(t)
+	g.renderer.coloredImage("die_1", 0, 0, [4]float32{1, 0, 0, 1})
+
+	img := g.images["die_1"]
+	x, y := opaquePixel(t, img)
+	b := img.Bounds()
+	got := dest.RGBAAt(x-b.Min.X, y-b.Min.Y)
+	r, _, _, _ := img.At(x, y).RGBA()
+	if got.R != uint8(r>>8) || got.G != 0 || got.B != 0 || got.A != 255 {
+		t.Errorf("colored pixel is %v", got)
+	}
+}
+
+func TestSoftwareRendererMeasuresText(t *testing.T) {
+	g, _ := softwareGraphics(t)
+	short, h := g.TextSize("ab")
+	long, _ := g.TextSize("abcd")
+	if short <= 0 || long != 2*short || float64(h) != fontSize {
+		t.Errorf("text sizes %v %v %v", short, long, h)
+	}
+}
+
+func softwareGraphics(t *testing.T) (*graphics, *image.RGBA) {
+	dest := image.NewRGBA(screenBounds)
+	g, err := newSoftwareGraphics(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g, dest
+}
+
+func opaquePixel(t *testing.T, img image.Image) (x, y int) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a == 0xFFFF {
+				return x, y
+			}
+		}
+	}
+	t.Fatal("image has no opaque pixel")
+	return
+}
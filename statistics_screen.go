@@ -0,0 +1,128 @@
+package main
+
+import (
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/lang"
+	"sort"
+	"strconv"
+)
+
+var (
+	statisticsBackColor    = [4]float32{0.1, 0.1, 0.2, 0.92}
+	statisticsFontColor    = [4]float32{1, 1, 1, 1}
+	statisticsBarColor     = [4]float32{0.5, 0.7, 1, 1}
+	statisticsExpectColor  = [4]float32{1, 0.85, 0, 1}
+	statisticsBlockedColor = [4]float32{1, 0.4, 0.4, 1}
+)
+
+// drawStatistics covers the screen with what the dice did so far: how often
+// every sum was rolled compared to what was to be expected, what each player
+// received and how often each tile produced or was blocked by the robber.
+func (g *graphics) drawStatistics(gm *game.Game) {
+	g.rect(-leftBorder, -topBorder, gameW+leftBorder+rightBorder,
+		gameH+topBorder+bottomBorder, statisticsBackColor)
+	g.writeTextLineCenteredInRect(lang.Get(lang.Statistics),
+		rect{0, -topBorder, gameW, topBorder}, statisticsFontColor)
+	g.drawRollHistogram(gm.RollCounts(), rect{50, 0, 650, 550})
+	g.drawIncome(gm, rect{800, 0, 550, 550})
+	g.drawTileRolls(gm, rect{50, 600, 1300, 550})
+}
+
+// drawRollHistogram draws one bar per sum, a marker on each bar shows how often
+// the sum should have come up on average.
+func (g *graphics) drawRollHistogram(counts [13]int, r rect) {
+	g.writeLeftAlignedVerticallyCenteredAt(lang.Get(lang.DiceRolls), r.x, r.y+25, statisticsFontColor)
+
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	expected := func(sum int) float64 {
+		return float64(total*game.Pips(sum)) / 36
+	}
+	maxValue := expected(7)
+	for _, n := range counts {
+		if float64(n) > maxValue {
+			maxValue = float64(n)
+		}
+	}
+	if maxValue == 0 {
+		maxValue = 1
+	}
+
+	const chartTop, chartH = 70, 330
+	baseline := r.y + chartTop + chartH
+	cellW := r.w / 11
+	for sum := 2; sum <= 12; sum++ {
+		x := r.x + (sum-2)*cellW
+		barH := int(float64(chartH)*float64(counts[sum])/maxValue + 0.5)
+		g.rect(x+7, baseline-barH, cellW-14, barH, statisticsBarColor)
+		expectY := baseline - int(float64(chartH)*expected(sum)/maxValue+0.5)
+		g.rect(x+2, expectY-2, cellW-4, 4, statisticsExpectColor)
+		g.writeTextLineCenteredInRect(strconv.Itoa(sum),
+			rect{x, baseline + 5, cellW, 50}, statisticsFontColor)
+	}
+
+	// the legend
+	legendY := baseline + 100
+	g.rect(r.x, legendY-15, 30, 30, statisticsBarColor)
+	g.writeLeftAlignedVerticallyCenteredAt(lang.Get(lang.Rolled), r.x+40, legendY, statisticsFontColor)
+	g.rect(r.x+r.w/2, legendY-2, 30, 4, statisticsExpectColor)
+	g.writeLeftAlignedVerticallyCenteredAt(lang.Get(lang.Expected), r.x+r.w/2+40, legendY, statisticsFontColor)
+}
+
+// drawIncome draws a table with all resources that the players received.
+func (g *graphics) drawIncome(gm *game.Game, r rect) {
+	g.writeLeftAlignedVerticallyCenteredAt(lang.Get(lang.Income), r.x, r.y+25, statisticsFontColor)
+
+	const rowH, swatch = 80, 40
+	cellW := (r.w - swatch - 20) / game.ResourceCount
+	left := r.x + swatch + 20
+	y := r.y + 70 + rowH/2
+	for i := 0; i < game.ResourceCount; i++ {
+		id := resourceToString(game.Resource(i)) + "_symbol"
+		g.drawImageCenteredAt(id, left+i*cellW+cellW/2, y)
+	}
+	for _, p := range gm.GetPlayers() {
+		y += rowH
+		g.rect(r.x, y-swatch/2, swatch, swatch, playerColor(p.Color))
+		g.drawPlayerMarker(r.x+swatch/2, y, p.Color, 4)
+		for i, n := range gm.Income(p.Color) {
+			g.writeTextLineCenteredInRect(strconv.Itoa(n),
+				rect{left + i*cellW, y - rowH/2, cellW, rowH}, statisticsFontColor)
+		}
+	}
+}
+
+// drawTileRolls draws every tile with a number and how often it produced and
+// how often the robber blocked it.
+func (g *graphics) drawTileRolls(gm *game.Game, r rect) {
+	g.writeLeftAlignedVerticallyCenteredAt(lang.Get(lang.TileRolls), r.x, r.y+25, statisticsFontColor)
+
+	var tiles []game.Tile
+	for _, t := range gm.Tiles {
+		if t.Number != 0 {
+			tiles = append(tiles, t)
+		}
+	}
+	sort.SliceStable(tiles, func(i, j int) bool { return tiles[i].Number < tiles[j].Number })
+
+	const columns, cellH = 6, 150
+	cellW := r.w / columns
+	for i, t := range tiles {
+		x := r.x + (i%columns)*cellW
+		y := r.y + 60 + (i/columns)*cellH
+		centerX := x + cellW/2
+		g.drawImageCenteredAt("number_plate", centerX-45, y+45)
+		g.drawImageCenteredAt(strconv.Itoa(t.Number), centerX-45, y+45)
+		g.drawImageCenteredAt(resourceToString(t.Resource())+"_symbol", centerX+45, y+45)
+
+		produced, blocked := gm.TileRolls(t.Position)
+		producedText := strconv.Itoa(produced) + " / "
+		producedW, _ := g.TextSize(producedText)
+		blockedW, _ := g.TextSize(strconv.Itoa(blocked))
+		textX := centerX - (producedW+blockedW)/2
+		g.writeLeftAlignedVerticallyCenteredAt(producedText, textX, y+115, statisticsFontColor)
+		g.writeLeftAlignedVerticallyCenteredAt(strconv.Itoa(blocked), textX+producedW, y+115, statisticsBlockedColor)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+)
+
+// A theme is read from a JSON file in the themes folder. It sets the font, the
+// colors and the sizes of the widgets by their names in themeColors and
+// themeMetrics. Everything that a theme leaves out keeps its built-in value, so
+// switching themes always starts from those. The font is only used when the
+// graphics are created, so a new font needs a restart.
+type theme struct {
+	Font     string
+	FontSize float64
+	Colors   map[string][4]float32
+	Metrics  map[string]int
+}
+
+var themeColors = map[string]*[4]float32{
+	"clear":             &clearColor,
+	"menuHotBack":       &menuHotBackColor,
+	"menuColdBack":      &menuColdBackColor,
+	"menuFont":          &menuFontColor,
+	"menuDisabledFont":  &menuDisabledFontColor,
+	"menuColdFont":      &menuColdFontColor,
+	"checkBoxChecked":   &checkBoxCheckedColor,
+	"checkBoxUnchecked": &checkBoxUncheckedColor,
+	"focusFrame":        &focusFrameColor,
+	"textLogBack":       &textLogBackColor,
+	"textLogFont":       &textLogFontColor,
+	"dialogShade":       &dialogShadeColor,
+	"dialogBack":        &dialogBackColor,
+	"dialogTitle":       &dialogTitleColor,
+	"tableBack":         &tableBackColor,
+	"tableHeader":       &tableHeaderColor,
+	"tableHotRow":       &tableHotRowColor,
+	"tableSelected":     &tableSelectedColor,
+	"tableFont":         &tableFontColor,
+	"tableScroll":       &tableScrollColor,
+	"pickerBack":        &pickerBackColor,
+	"pickerFocus":       &pickerFocusColor,
+	"pickerCount":       &pickerCountColor,
+	"pickerLimit":       &pickerLimitColor,
+	"instructionBack":   &instructionBackColor,
+	"resourceBarBack":   &resourceBarBackColor,
+	"buyMenuBack":       &buyMenuBackColor,
+	"panelBack":         &panelBackColor,
+	"panelCurrentBack":  &panelCurrentBackColor,
+	"panelFrame":        &panelFrameColor,
+	"panelFont":         &panelFontColor,
+	"statisticsBack":    &statisticsBackColor,
+	"statisticsFont":    &statisticsFontColor,
+	"statisticsBar":     &statisticsBarColor,
+	"statisticsExpect":  &statisticsExpectColor,
+	"statisticsBlocked": &statisticsBlockedColor,
+	"tooltipBack":       &tooltipBackColor,
+	"tooltipFrame":      &tooltipFrameColor,
+	"tooltipText":       &tooltipTextColor,
+}
+
+var themeMetrics = map[string]*int{
+	"buttonW":             &buttonW,
+	"buttonH":             &buttonH,
+	"focusFrameWidth":     &focusFrameWidth,
+	"dialogPadding":       &dialogPadding,
+	"dialogTitleH":        &dialogTitleH,
+	"dialogButtonW":       &dialogButtonW,
+	"dialogButtonH":       &dialogButtonH,
+	"dialogButtonSpacing": &dialogButtonSpacing,
+	"tableRowH":           &tableRowH,
+	"tableMargin":         &tableMargin,
+	"pickerButtonMargin":  &pickerButtonMargin,
+	"tooltipBorder":       &tooltipBorder,
+}
+
+// builtInTheme has the values that the variables had before any theme was
+// applied.
+var builtInTheme = currentTheme()
+
+func currentTheme() *theme {
+	t := &theme{
+		Font:     fontFile,
+		FontSize: fontSize,
+		Colors:   make(map[string][4]float32),
+		Metrics:  make(map[string]int),
+	}
+	for name, c := range themeColors {
+		t.Colors[name] = *c
+	}
+	for name, m := range themeMetrics {
+		t.Metrics[name] = *m
+	}
+	return t
+}
+
+// loadTheme reads themes/<name>.json.
+func loadTheme(name string) (*theme, error) {
+	file, err := os.Open(resourcePath("themes", name+".json"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	t, err := readTheme(file)
+	if err != nil {
+		return nil, errors.New("theme " + name + ": " + err.Error())
+	}
+	return t, nil
+}
+
+// readTheme decodes a theme, it is an error if it names colors or metrics that
+// do not exist.
+func readTheme(r io.Reader) (*theme, error) {
+	var t theme
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return nil, err
+	}
+	for name := range t.Colors {
+		if _, ok := themeColors[name]; !ok {
+			return nil, errors.New("unknown color " + name)
+		}
+	}
+	for name, m := range t.Metrics {
+		if _, ok := themeMetrics[name]; !ok {
+			return nil, errors.New("unknown metric " + name)
+		}
+		if m < 0 {
+			return nil, errors.New("negative metric " + name)
+		}
+	}
+	if t.FontSize < 0 {
+		return nil, errors.New("negative font size")
+	}
+	return &t, nil
+}
+
+// apply resets the colors and metrics to the built-in theme and then sets what
+// this theme defines.
+func (t *theme) apply() {
+	if t != builtInTheme {
+		builtInTheme.apply()
+	}
+	for name, c := range t.Colors {
+		*themeColors[name] = c
+	}
+	for name, m := range t.Metrics {
+		*themeMetrics[name] = m
+	}
+}
+
+// applyFont sets the font for the graphics that are created next.
+func (t *theme) applyFont() {
+	fontFile, fontSize = builtInTheme.Font, builtInTheme.FontSize
+	if t.Font != "" {
+		fontFile = t.Font
+	}
+	if t.FontSize != 0 {
+		fontSize = t.FontSize
+	}
+}
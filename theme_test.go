@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultThemeFileHasTheBuiltInValues(t *testing.T) {
+	def, err := loadTheme("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Font != builtInTheme.Font || def.FontSize != builtInTheme.FontSize {
+		t.Errorf("font is %s %v", def.Font, def.FontSize)
+	}
+	for name, c := range builtInTheme.Colors {
+		if def.Colors[name] != c {
+			t.Errorf("color %s is %v but built in %v", name, def.Colors[name], c)
+		}
+	}
+	for name, m := range builtInTheme.Metrics {
+		if def.Metrics[name] != m {
+			t.Errorf("metric %s is %v but built in %v", name, def.Metrics[name], m)
+		}
+	}
+}
+
+func TestHighContrastThemeSetsEverything(t *testing.T) {
+	defer builtInTheme.apply()
+	high, err := loadTheme("high_contrast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range themeColors {
+		if _, ok := high.Colors[name]; !ok {
+			t.Errorf("color %s is missing", name)
+		}
+	}
+	for name := range themeMetrics {
+		if _, ok := high.Metrics[name]; !ok {
+			t.Errorf("metric %s is missing", name)
+		}
+	}
+
+	high.apply()
+	if menuFontColor != high.Colors["menuFont"] {
+		t.Error("applying the theme did not set the colors")
+	}
+}
+
+func TestThemesStartFromTheBuiltInValues(t *testing.T) {
+	defer builtInTheme.apply()
+	dark, err := readTheme(strings.NewReader(`{"Colors": {"clear": [0, 0, 0, 1]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	big, err := readTheme(strings.NewReader(`{"Metrics": {"buttonH": 100}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dark.apply()
+	big.apply()
+	if buttonH != 100 || clearColor != builtInTheme.Colors["clear"] {
+		t.Errorf("button height %d, clear color %v", buttonH, clearColor)
+	}
+}
+
+func TestUnknownNamesInThemesAreErrors(t *testing.T) {
+	for _, theme := range []string{
+		`{"Colors": {"noSuchColor": [0, 0, 0, 1]}}`,
+		`{"Metrics": {"noSuchMetric": 1}}`,
+		`{"Metrics": {"buttonW": -1}}`,
+	} {
+		if _, err := readTheme(strings.NewReader(theme)); err == nil {
+			t.Errorf("no error for %s", theme)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/lang"
+	"math"
+)
+
+var tooltipBorder = 10
+
+const (
+	// tooltipDelay is how many frames the mouse has to rest on something
+	// before its tooltip shows.
+	tooltipDelay = 30
+	// tooltipOffset is the distance of the tooltip to the mouse cursor, so the
+	// cursor does not cover the text.
+	tooltipOffset = 20
+	// numberPlateRadius is how far from the tile center the number plate
+	// reaches.
+	numberPlateRadius = 36
+	// harborHitRadius is how far from the water tile center the harbor sign
+	// reaches.
+	harborHitRadius = 45
+)
+
+var (
+	tooltipBackColor  = [4]float32{1, 1, 0.85, 0.95}
+	tooltipFrameColor = [4]float32{0.3, 0.2, 0.1, 1}
+	tooltipTextColor  = [4]float32{0, 0, 0, 1}
+)
+
+// A tooltip explains what is under the mouse. It shows when the mouse rests on
+// the same thing for a while and moves with the mouse.
+type tooltip struct {
+	lines []string
+	x, y  int
+	// frames counts for how long the lines did not change
+	frames int
+}
+
+// update tells the tooltip what to show for the mouse position, no lines mean
+// that there is nothing to explain there.
+func (t *tooltip) update(x, y int, lines []string) {
+	if !sameLines(t.lines, lines) {
+		t.frames = 0
+	}
+	t.lines = lines
+	t.x, t.y = x, y
+	t.frames++
+}
+
+func sameLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *tooltip) visible() bool {
+	return len(t.lines) > 0 && t.frames > tooltipDelay
+}
+
+// bounds returns where the tooltip goes, to the bottom right of the mouse. It
+// goes to the other side of the mouse where it would leave the area.
+func (t *tooltip) bounds(font textSizer, area rect) rect {
+	r := rect{w: 2 * tooltipBorder, h: 2 * tooltipBorder}
+	for _, line := range t.lines {
+		w, h := font.TextSize(line)
+		if w+2*tooltipBorder > r.w {
+			r.w = w + 2*tooltipBorder
+		}
+		r.h += h
+	}
+	r.x, r.y = t.x+tooltipOffset, t.y+tooltipOffset
+	if r.x+r.w > area.x+area.w {
+		r.x = t.x - tooltipOffset - r.w
+	}
+	if r.y+r.h > area.y+area.h {
+		r.y = t.y - tooltipOffset - r.h
+	}
+	return r
+}
+
+func (t *tooltip) draw(g *graphics, area rect) {
+	if !t.visible() {
+		return
+	}
+	r := t.bounds(g, area)
+	g.rect(r.x, r.y, r.w, r.h, tooltipBackColor)
+	g.frame(r, 2, tooltipFrameColor)
+	y := r.y + tooltipBorder
+	for _, line := range t.lines {
+		_, h := g.TextSize(line)
+		g.writeLeftAlignedVerticallyCenteredAt(line, r.x+tooltipBorder, y+h/2, tooltipTextColor)
+		y += h
+	}
+}
+
+// tooltipAt returns the tooltip for what is at the window position, that is
+// either a line of the buy menu or something on the board.
+func (ui *gameUI) tooltipAt(windowX, windowY float64) []string {
+	if ui.game.State == game.NotStarted || ui.topDialog() != nil ||
+		ui.showingStatistics || ui.draggingBoard {
+		return nil
+	}
+	hudX, hudY := ui.camera.windowToHUD(windowX, windowY)
+	if ui.hud.buyMenuWindow.visible {
+		if lines := ui.hud.buyMenu.tooltip(hudX, hudY); lines != nil {
+			return lines
+		}
+	}
+	if ui.hud.covers(hudX, hudY) {
+		return nil
+	}
+	return ui.boardTooltip(ui.camera.windowToGame(windowX, windowY))
+}
+
+// boardTooltip tells who owns the building of another player, how often a
+// number comes up or what a harbor trades at the board position.
+func (ui *gameUI) boardTooltip(x, y int) []string {
+	if corner, hit := screenToCorner(x, y); hit {
+		if owner, ok := ui.buildingOwnerTooltip(corner); ok {
+			return []string{owner}
+		}
+	}
+	for _, tile := range ui.game.Tiles {
+		tileX, tileY, w, h := tileToScreen(tile.Position)
+		d := math.Hypot(float64(x-(tileX+w/2)), float64(y-(tileY+h/2)))
+		if tile.Number != 0 && d <= numberPlateRadius {
+			return []string{numberTooltip(tile.Number)}
+		}
+		if tile.Terrain == game.Water && tile.Harbor.Kind != game.NoHarbor &&
+			d <= harborHitRadius {
+			return []string{harborTooltip(tile.Harbor.Kind)}
+		}
+	}
+	return nil
+}
+
+// buildingOwnerTooltip names the owner of the settlement or city at the
+// corner. There is none for the buildings of the player at this computer.
+func (ui *gameUI) buildingOwnerTooltip(corner game.TileCorner) (string, bool) {
+	self := ui.shownPlayer().Color
+	for _, p := range ui.game.GetPlayers() {
+		if p.Color == self {
+			continue
+		}
+		for _, s := range p.GetBuiltSettlements() {
+			if s.Position == corner {
+				return fmt.Sprintf(lang.Get(lang.SettlementOwnerTooltip), ui.playerName(p.Color)), true
+			}
+		}
+		for _, c := range p.GetBuiltCities() {
+			if c.Position == corner {
+				return fmt.Sprintf(lang.Get(lang.CityOwnerTooltip), ui.playerName(p.Color)), true
+			}
+		}
+	}
+	return "", false
+}
+
+func numberTooltip(number int) string {
+	pips := game.Pips(number)
+	percent := int(math.Floor(float64(pips)*100/36 + 0.5))
+	return fmt.Sprintf(lang.Get(lang.NumberTooltip), number, pips, percent)
+}
+
+func harborTooltip(kind game.HarborKind) string {
+	if kind == game.ThreeToOneHarbor {
+		return lang.Get(lang.ThreeToOneHarborTooltip)
+	}
+	return fmt.Sprintf(lang.Get(lang.TwoToOneHarborTooltip), resourceName(harborResource(kind)))
+}
+
+// harborResource returns the resource that a 2:1 harbor trades.
+func harborResource(kind game.HarborKind) game.Resource {
+	switch kind {
+	case game.WoolHarbor:
+		return game.Wool
+	case game.LumberHarbor:
+		return game.Lumber
+	case game.BrickHarbor:
+		return game.Brick
+	case game.OreHarbor:
+		return game.Ore
+	case game.GrainHarbor:
+		return game.Grain
+	}
+	panic("not a 2:1 harbor")
+}
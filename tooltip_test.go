@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/gonutz/settlers/game"
+	"github.com/gonutz/settlers/lang"
+	"testing"
+)
+
+func TestTooltipShowsAfterTheMouseRested(t *testing.T) {
+	var tip tooltip
+	for i := 0; i < tooltipDelay; i++ {
+		tip.update(10, 10, []string{"a"})
+	}
+	if tip.visible() {
+		t.Fatal("tooltip shows before the delay")
+	}
+	tip.update(12, 10, []string{"a"})
+	if !tip.visible() {
+		t.Fatal("tooltip does not show after the delay")
+	}
+	tip.update(12, 10, []string{"b"})
+	if tip.visible() {
+		t.Error("new text shows without the delay")
+	}
+	tip.update(12, 10, nil)
+	if tip.visible() {
+		t.Error("tooltip without text shows")
+	}
+}
+
+func TestTooltipStaysInsideTheArea(t *testing.T) {
+	font := &softwareRenderer{}
+	area := rect{0, 0, 1000, 800}
+	tip := tooltip{lines: []string{"tooltip"}, x: 100, y: 100}
+	if r := tip.bounds(font, area); r.x <= tip.x || r.y <= tip.y {
+		t.Errorf("tooltip is at %v, not to the bottom right of the mouse", r)
+	}
+	tip.x, tip.y = 990, 790
+	if r := tip.bounds(font, area); r.x+r.w > tip.x || r.y+r.h > tip.y {
+		t.Errorf("tooltip is at %v, not to the top left of the mouse", r)
+	}
+}
+
+func TestBuyMenuTooltipsShowCostAndEffect(t *testing.T) {
+	defer func(l lang.Language) { lang.CurrentLanguage = l }(lang.CurrentLanguage)
+	lang.CurrentLanguage = lang.English
+	m := newBuyMenu(nil)
+	m.state, m.xOffset = opened, m.right
+	main := m.mainRect()
+	lines := m.tooltip(main.x+10, main.y+2*cellH+10)
+	want := []string{"City", lang.Get(lang.CityEffect), "Cost: 2 grain, 3 ore"}
+	if !sameLines(lines, want) {
+		t.Errorf("city line has tooltip %q", lines)
+	}
+	m.state = closing
+	if lines := m.tooltip(main.x+10, main.y+10); lines != nil {
+		t.Errorf("closing menu has tooltip %q", lines)
+	}
+}
+
+func TestBoardTooltips(t *testing.T) {
+	defer func(l lang.Language) { lang.CurrentLanguage = l }(lang.CurrentLanguage)
+	lang.CurrentLanguage = lang.English
+	g := goldenGame()
+	ui := &gameUI{game: g}
+	tileCenter := func(match func(game.Tile) bool) (x, y int) {
+		for _, tile := range g.Tiles {
+			if match(tile) {
+				x, y, w, h := tileToScreen(tile.Position)
+				return x + w/2, y + h/2
+			}
+		}
+		t.Fatal("no such tile")
+		return
+	}
+
+	x, y := tileCenter(func(tile game.Tile) bool { return tile.Number == 8 })
+	checkTooltip(t, ui.boardTooltip(x, y), "8 comes up in 5 of 36 rolls (14%)")
+
+	x, y = tileCenter(func(tile game.Tile) bool { return tile.Harbor.Kind == game.ThreeToOneHarbor })
+	checkTooltip(t, ui.boardTooltip(x, y), lang.Get(lang.ThreeToOneHarborTooltip))
+	x, y = tileCenter(func(tile game.Tile) bool { return tile.Harbor.Kind == game.OreHarbor })
+	checkTooltip(t, ui.boardTooltip(x, y), "2:1 harbor: trade 2 ore for any one resource")
+
+	// the first player is the current one and sees who owns the other
+	// buildings
+	x, y = cornerToScreen(game.TileCorner{X: 7, Y: 4})
+	checkTooltip(t, ui.boardTooltip(x, y), "Settlement of "+ui.playerName(g.Players[1].Color))
+	x, y = cornerToScreen(game.TileCorner{X: 9, Y: 3})
+	checkTooltip(t, ui.boardTooltip(x, y), "City of "+ui.playerName(g.Players[2].Color))
+	x, y = cornerToScreen(game.TileCorner{X: 8, Y: 2})
+	if lines := ui.boardTooltip(x, y); len(lines) == 1 && lines[0] == "City of "+ui.playerName(g.Players[0].Color) {
+		t.Error("own city has an owner tooltip")
+	}
+}
+
+func checkTooltip(t *testing.T, lines []string, want string) {
+	if len(lines) != 1 || lines[0] != want {
+		t.Errorf("tooltip is %q but should be %q", lines, want)
+	}
+}